@@ -0,0 +1,146 @@
+package alias
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// Alias generation strategies
+const (
+	StrategyRandom   = "random"
+	StrategySequence = "sequence"
+	StrategyWords    = "words"
+)
+
+const (
+	// MaxAttempts bounds how often a generated alias that is already taken is replaced by a new one
+	MaxAttempts = 5
+
+	DefaultLength = 8
+	DefaultWords  = 3
+
+	minLength = 4
+	maxLength = 32
+	maxWords  = 6
+
+	base62 = "0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
+)
+
+// Generator creates aliases for links created without a custom alias
+type Generator interface {
+	Generate(ctx context.Context) (string, error)
+}
+
+// Counter hands out the numbers encoded by the sequence strategy, every call returns a new one
+type Counter interface {
+	NextAliasSequence(ctx context.Context) (int64, error)
+}
+
+// Options configure the generators, zero values use the defaults
+type Options struct {
+	// Strategy used when a request does not pick one, random when empty
+	Strategy string
+	// Length of random aliases and the minimum length of sequence aliases
+	Length int
+	// Number of words in word aliases
+	Words int
+	// Salt of the sequence strategy, changing it changes every alias it creates
+	Salt string
+}
+
+// Generators holds one generator per strategy
+type Generators struct {
+	strategy   string
+	generators map[string]Generator
+}
+
+// New returns the generators for opts, the sequence strategy draws its numbers from counter.
+// Generated aliases blocked by policy are replaced, a nil policy allows every alias.
+func New(opts Options, counter Counter, policy *Policy) (*Generators, error) {
+	strategy := strings.ToLower(strings.TrimSpace(opts.Strategy))
+	if strategy == "" {
+		strategy = StrategyRandom
+	}
+
+	length := opts.Length
+	if length == 0 {
+		length = DefaultLength
+	}
+	if length < minLength || length > maxLength {
+		return nil, fmt.Errorf("alias length must be between %d and %d", minLength, maxLength)
+	}
+
+	words := opts.Words
+	if words == 0 {
+		words = DefaultWords
+	}
+	if words < 2 || words > maxWords {
+		return nil, fmt.Errorf("alias words must be between 2 and %d", maxWords)
+	}
+
+	g := &Generators{
+		strategy: strategy,
+		generators: map[string]Generator{
+			StrategyRandom:   Random{Length: length},
+			StrategySequence: NewSequence(counter, opts.Salt, length),
+			StrategyWords:    Words{Count: words},
+		},
+	}
+	if _, ok := g.generators[strategy]; !ok {
+		return nil, fmt.Errorf("unknown alias strategy %q", opts.Strategy)
+	}
+	if policy != nil {
+		for name, generator := range g.generators {
+			g.generators[name] = filtered{generator: generator, policy: policy}
+		}
+	}
+	return g, nil
+}
+
+// filtered skips generated aliases the policy blocks
+type filtered struct {
+	generator Generator
+	policy    *Policy
+}
+
+func (f filtered) Generate(ctx context.Context) (string, error) {
+	for attempt := 1; ; attempt++ {
+		alias, err := f.generator.Generate(ctx)
+		if err != nil || !f.policy.Blocks(alias) {
+			return alias, err
+		}
+		if attempt == MaxAttempts {
+			return "", fmt.Errorf("no allowed alias after %d attempts", MaxAttempts)
+		}
+	}
+}
+
+// Strategy returns the name of the default strategy
+func (g *Generators) Strategy() string {
+	return g.strategy
+}
+
+// Get returns the generator of strategy, an empty strategy is the default one
+func (g *Generators) Get(strategy string) (Generator, error) {
+	strategy = strings.ToLower(strings.TrimSpace(strategy))
+	if strategy == "" {
+		strategy = g.strategy
+	}
+	generator, ok := g.generators[strategy]
+	if !ok {
+		return nil, fmt.Errorf("alias_strategy must be one of %s, %s or %s", StrategyRandom, StrategySequence, StrategyWords)
+	}
+	return generator, nil
+}
+
+// randomIndex returns a uniformly chosen number below n
+func randomIndex(n int) (int, error) {
+	i, err := rand.Int(rand.Reader, big.NewInt(int64(n)))
+	if err != nil {
+		return 0, err
+	}
+	return int(i.Int64()), nil
+}
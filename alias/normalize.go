@@ -0,0 +1,42 @@
+package alias
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/unicode/norm"
+)
+
+// lookalikes maps Cyrillic and Greek letters to the Latin letter they are mistaken for
+var lookalikes = strings.NewReplacer(
+	"а", "a", "в", "b", "е", "e", "һ", "h", "і", "i", "ј", "j", "к", "k", "ӏ", "l", "м", "m", "н", "h",
+	"о", "o", "р", "p", "ԛ", "q", "с", "c", "ѕ", "s", "т", "t", "у", "y", "ԝ", "w", "х", "x", "ԁ", "d",
+	"α", "a", "β", "b", "ε", "e", "η", "n", "ι", "i", "κ", "k", "ν", "v", "ο", "o", "ρ", "p", "τ", "t",
+	"υ", "u", "χ", "x", "ɡ", "g",
+)
+
+var folder = cases.Fold()
+
+// Normalize returns the NFC form aliases are stored and looked up in
+func Normalize(alias string) string {
+	return norm.NFC.String(alias)
+}
+
+// Key returns the form aliases are matched in when matching ignores case: compatibility characters such as
+// fullwidth letters are decomposed, case is folded and look-alike letters of other scripts become Latin
+func Key(alias string) string {
+	return lookalikes.Replace(folder.String(norm.NFKC.String(alias)))
+}
+
+// mixesScripts reports whether alias has letters of more than one of the Latin, Cyrillic and Greek scripts,
+// which is how look-alike aliases such as pаypal with a Cyrillic а are made
+func mixesScripts(alias string) bool {
+	scripts := 0
+	for _, script := range []*unicode.RangeTable{unicode.Latin, unicode.Cyrillic, unicode.Greek} {
+		if strings.IndexFunc(alias, func(r rune) bool { return unicode.Is(script, r) }) >= 0 {
+			scripts++
+		}
+	}
+	return scripts > 1
+}
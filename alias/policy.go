@@ -0,0 +1,241 @@
+package alias
+
+import (
+	"bufio"
+	_ "embed"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Policy violation codes
+const (
+	ViolationTooShort   = "too_short"
+	ViolationTooLong    = "too_long"
+	ViolationCharacters = "invalid_characters"
+	ViolationReserved   = "reserved"
+	ViolationProfanity  = "profanity"
+	ViolationConfusable = "confusable"
+)
+
+// zeroWidthJoiner glues emoji into a single symbol, such as a family
+const zeroWidthJoiner = '\u200d'
+
+const (
+	DefaultMinLength = 3
+	DefaultMaxLength = 64
+)
+
+// builtinReserved are served by middleware rather than routes, so they are not found among the routes
+var builtinReserved = []string{"livez", "readyz"}
+
+//go:embed profanity.txt
+var profanityList string
+
+// digit substitutions undone before looking for profanity
+var leet = strings.NewReplacer("0", "o", "1", "i", "3", "e", "4", "a", "5", "s", "7", "t", "8", "b")
+
+// wholeWordPrefix marks profanity list entries only blocked as a whole part of an alias
+const wholeWordPrefix = "="
+
+// Violation is a single rule an alias breaks
+type Violation struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// PolicyError lists every rule an alias breaks
+type PolicyError struct {
+	Alias      string
+	Violations []Violation
+}
+
+func (e *PolicyError) Error() string {
+	messages := make([]string, len(e.Violations))
+	for i, violation := range e.Violations {
+		messages[i] = violation.Message
+	}
+	return fmt.Sprintf("alias %q is not allowed: %s", e.Alias, strings.Join(messages, "; "))
+}
+
+// Policy decides which custom aliases users may choose. Aliases are 3 to 64 letters, digits, emoji, - or _
+// by default, and may not be a reserved word, contain profanity or mix look-alike scripts.
+type Policy struct {
+	MinLength int
+	MaxLength int
+	// CaseInsensitive matches aliases by their Key, so aliases differing only in case or look-alike letters are the same
+	CaseInsensitive bool
+
+	reserved  map[string]bool
+	profanity []string
+	// profane words only blocked as a whole part of an alias
+	profaneWords map[string]bool
+}
+
+// NewPolicy returns a policy for aliases between minLength and maxLength characters, zero values use the defaults.
+// reservedFile optionally names a file of reserved aliases, one per line, lines starting with # are ignored.
+func NewPolicy(minLength, maxLength int, reservedFile string) (*Policy, error) {
+	if minLength == 0 {
+		minLength = DefaultMinLength
+	}
+	if maxLength == 0 {
+		maxLength = DefaultMaxLength
+	}
+	if minLength < 1 || maxLength < minLength {
+		return nil, fmt.Errorf("alias length limits %d to %d are invalid", minLength, maxLength)
+	}
+
+	list, err := readList(strings.NewReader(profanityList))
+	if err != nil {
+		return nil, err
+	}
+
+	p := &Policy{
+		MinLength:    minLength,
+		MaxLength:    maxLength,
+		reserved:     make(map[string]bool),
+		profaneWords: make(map[string]bool),
+	}
+	for _, word := range list {
+		if whole, ok := strings.CutPrefix(word, wholeWordPrefix); ok {
+			p.profaneWords[whole] = true
+		} else {
+			p.profanity = append(p.profanity, word)
+		}
+	}
+	p.Reserve(builtinReserved...)
+
+	if reservedFile != "" {
+		f, err := os.Open(reservedFile)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+
+		words, err := readList(f)
+		if err != nil {
+			return nil, err
+		}
+		p.Reserve(words...)
+	}
+	return p, nil
+}
+
+// Reserve stops words from being used as aliases, reserved words are matched by their Key
+func (p *Policy) Reserve(words ...string) {
+	for _, word := range words {
+		if word = strings.TrimSpace(word); word != "" {
+			p.reserved[Key(word)] = true
+		}
+	}
+}
+
+// ReservePaths reserves the first static segment of every route path, such as api for /api/v1/links
+func (p *Policy) ReservePaths(paths []string) {
+	for _, path := range paths {
+		segment, _, _ := strings.Cut(strings.TrimPrefix(path, "/"), "/")
+		if segment == "" || strings.ContainsAny(segment[:1], ":*+") {
+			continue
+		}
+		p.Reserve(segment)
+	}
+}
+
+// Check returns a *PolicyError listing every rule alias breaks, nil when it is allowed
+func (p *Policy) Check(alias string) error {
+	var violations []Violation
+
+	if n := len([]rune(alias)); n < p.MinLength {
+		violations = append(violations, Violation{ViolationTooShort, fmt.Sprintf("must be at least %d characters", p.MinLength)})
+	} else if n > p.MaxLength {
+		violations = append(violations, Violation{ViolationTooLong, fmt.Sprintf("must be at most %d characters", p.MaxLength)})
+	}
+	if strings.IndexFunc(alias, invalidRune) >= 0 {
+		violations = append(violations, Violation{ViolationCharacters, "may only contain letters, digits, emoji, - and _"})
+	}
+	if mixesScripts(alias) {
+		violations = append(violations, Violation{ViolationConfusable, "mixes look-alike letters of different scripts"})
+	}
+	if !norm.NFKC.IsNormalString(alias) {
+		violations = append(violations, Violation{ViolationConfusable, "uses compatibility characters such as fullwidth letters"})
+	}
+	if p.isReserved(alias) {
+		violations = append(violations, Violation{ViolationReserved, "is reserved"})
+	}
+	if p.isProfane(alias) {
+		violations = append(violations, Violation{ViolationProfanity, "contains a blocked word"})
+	}
+
+	if len(violations) == 0 {
+		return nil
+	}
+	return &PolicyError{Alias: alias, Violations: violations}
+}
+
+// Blocks reports whether alias is reserved or contains profanity, generated aliases that are get replaced
+func (p *Policy) Blocks(alias string) bool {
+	return p.isReserved(alias) || p.isProfane(alias)
+}
+
+// MatchKey returns the form two aliases must share to be the same alias under the policy
+func (p *Policy) MatchKey(alias string) string {
+	if p.CaseInsensitive {
+		return Key(alias)
+	}
+	return alias
+}
+
+func (p *Policy) isReserved(alias string) bool {
+	return p.reserved[Key(alias)]
+}
+
+// isProfane checks every part of alias between - and _ on its own, so words are never made up of
+// the end of one part and the start of the next (push-it, this-hit)
+func (p *Policy) isProfane(alias string) bool {
+	parts := strings.FieldsFunc(Key(alias), func(r rune) bool { return r == '-' || r == '_' })
+	for _, part := range parts {
+		if p.isProfanePart(part) || p.isProfanePart(leet.Replace(part)) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *Policy) isProfanePart(part string) bool {
+	if p.profaneWords[part] || p.profaneWords[strings.TrimSuffix(part, "s")] {
+		return true
+	}
+	for _, word := range p.profanity {
+		if strings.Contains(part, word) {
+			return true
+		}
+	}
+	return false
+}
+
+func invalidRune(r rune) bool {
+	if r < unicode.MaxASCII {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_')
+	}
+	// letters and digits of any script, combining marks, and emoji with their modifiers and joiners
+	return !(unicode.IsLetter(r) || unicode.Is(unicode.Nd, r) || unicode.In(r, unicode.Mn, unicode.Mc, unicode.Me) ||
+		unicode.In(r, unicode.So, unicode.Sk) || r == zeroWidthJoiner)
+}
+
+// readList returns the non empty lines of r that are not comments, lowercased
+func readList(r io.Reader) ([]string, error) {
+	var words []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.ToLower(strings.TrimSpace(scanner.Text()))
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		words = append(words, line)
+	}
+	return words, scanner.Err()
+}
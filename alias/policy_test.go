@@ -0,0 +1,74 @@
+package alias
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func violationCodes(err error) []string {
+	var policyErr *PolicyError
+	if !errors.As(err, &policyErr) {
+		return nil
+	}
+	var codes []string
+	for _, violation := range policyErr.Violations {
+		codes = append(codes, violation.Code)
+	}
+	return codes
+}
+
+func TestPolicyProfanity(t *testing.T) {
+	policy, err := NewPolicy(0, 0, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		alias   string
+		profane bool
+	}{
+		// short words inside harmless words or across parts
+		{"push-it", false},
+		{"this-hit", false},
+		{"grapes-hit", false},
+		{"Scunthorpe", false},
+		{"shitake", false},
+		{"pissarro", false},
+		{"swanky", false},
+		{"saltwater", false},
+		{"flame-retardant", false},
+		{"MotherFunction", false},
+		{"sniggering", false},
+		{"summer-sale", false},
+
+		// whole words, plurals and digit substitutions
+		{"shit", true},
+		{"holy-shit", true},
+		{"shits", true},
+		{"5h1t", true},
+		{"big_wank", true},
+		{"CUNT", true},
+		{"p1ss-off", true},
+
+		// long words anywhere in a part
+		{"fuckthis", true},
+		{"what-the-fuck", true},
+		{"b1tchy", true},
+		{"freeporn", true},
+		{"Motherfucker", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.alias, func(t *testing.T) {
+			if got := policy.isProfane(tt.alias); got != tt.profane {
+				t.Errorf("isProfane(%q) = %v, want %v", tt.alias, got, tt.profane)
+			}
+
+			profane := slices.Contains(violationCodes(policy.Check(tt.alias)), ViolationProfanity)
+			if profane != tt.profane {
+				t.Errorf("Check(%q) profanity violation = %v, want %v", tt.alias, profane, tt.profane)
+			}
+		})
+	}
+}
@@ -0,0 +1,22 @@
+package alias
+
+import (
+	"context"
+)
+
+// Random creates aliases of Length random base62 characters
+type Random struct {
+	Length int
+}
+
+func (r Random) Generate(_ context.Context) (string, error) {
+	buf := make([]byte, r.Length)
+	for i := range buf {
+		n, err := randomIndex(len(base62))
+		if err != nil {
+			return "", err
+		}
+		buf[i] = base62[n]
+	}
+	return string(buf), nil
+}
@@ -0,0 +1,179 @@
+package alias
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"math/big"
+	"strings"
+)
+
+// rounds of the Feistel network, an even number so both halves keep their width
+const rounds = 8
+
+var errInvalidAlias = errors.New("alias was not created by this sequence")
+
+// Sequence encodes numbers from a counter as aliases of at least MinLength characters.
+// Numbers are permuted by a Feistel network keyed by the salt over all characters of the alias and written
+// with a salt shuffled base62 alphabet, so consecutive links get unrelated aliases while Decode can still
+// recover the number.
+type Sequence struct {
+	Counter   Counter
+	MinLength int
+
+	alphabet string
+	key      [sha256.Size]byte
+}
+
+// NewSequence returns a sequence generator using salt to shuffle its alphabet and key its permutation
+func NewSequence(counter Counter, salt string, minLength int) *Sequence {
+	return &Sequence{
+		Counter:   counter,
+		MinLength: minLength,
+		alphabet:  shuffle(base62, salt),
+		key:       sha256.Sum256([]byte(salt)),
+	}
+}
+
+func (s *Sequence) Generate(ctx context.Context) (string, error) {
+	if s.Counter == nil {
+		return "", errors.New("alias sequence has no counter")
+	}
+	n, err := s.Counter.NextAliasSequence(ctx)
+	if err != nil {
+		return "", err
+	}
+	return s.Encode(n), nil
+}
+
+// Encode returns the alias of n, n must not be negative
+func (s *Sequence) Encode(n int64) string {
+	value := big.NewInt(n)
+	length := s.MinLength
+	for value.Cmp(space(length)) >= 0 {
+		length++
+	}
+
+	// the number is split into a left part of length/2 digits and a right part of the remaining digits,
+	// every round adds a keyed hash of one part to the other and swaps them
+	left, right := split(value, length)
+	for round := 0; round < rounds; round++ {
+		width := partWidth(length, round)
+		next := new(big.Int).Add(left, s.roundHash(round, right, width))
+		left, right = right, next.Mod(next, space(width))
+	}
+
+	digits := make([]int, length)
+	toDigits(join(left, right, length), digits)
+	buf := make([]byte, length)
+	for i, digit := range digits {
+		buf[i] = s.alphabet[digit]
+	}
+	return string(buf)
+}
+
+// Decode returns the number an alias was created from
+func (s *Sequence) Decode(alias string) (int64, error) {
+	if len(alias) < s.MinLength {
+		return 0, errInvalidAlias
+	}
+
+	digits := make([]int, len(alias))
+	for i := 0; i < len(alias); i++ {
+		digits[i] = strings.IndexByte(s.alphabet, alias[i])
+		if digits[i] < 0 {
+			return 0, errInvalidAlias
+		}
+	}
+
+	length := len(alias)
+	value := new(big.Int)
+	fromDigits(digits, value)
+
+	left, right := split(value, length)
+	for round := rounds - 1; round >= 0; round-- {
+		width := partWidth(length, round)
+		previous := new(big.Int).Sub(right, s.roundHash(round, left, width))
+		left, right = previous.Mod(previous, space(width)), left
+	}
+	value = join(left, right, length)
+
+	// numbers have a single encoding, a longer alias for a small number is not one of ours
+	if !value.IsInt64() || s.Encode(value.Int64()) != alias {
+		return 0, errInvalidAlias
+	}
+	return value.Int64(), nil
+}
+
+// partWidth is the number of digits of the part a round changes, the parts take turns
+func partWidth(length, round int) int {
+	if round%2 == 0 {
+		return length / 2
+	}
+	return length - length/2
+}
+
+// split returns the left length/2 digits and the remaining right digits of value
+func split(value *big.Int, length int) (*big.Int, *big.Int) {
+	left, right := new(big.Int), new(big.Int)
+	left.DivMod(value, space(length-length/2), right)
+	return left, right
+}
+
+// join is the inverse of split
+func join(left, right *big.Int, length int) *big.Int {
+	value := new(big.Int).Mul(left, space(length-length/2))
+	return value.Add(value, right)
+}
+
+// roundHash derives a number below 62^width from the key, the round and part
+func (s *Sequence) roundHash(round int, part *big.Int, width int) *big.Int {
+	h := sha256.New()
+	h.Write(s.key[:])
+	h.Write([]byte{byte(round)})
+	h.Write(part.Bytes())
+	sum := new(big.Int).SetBytes(h.Sum(nil))
+	return sum.Mod(sum, space(width))
+}
+
+// toDigits writes value as base62 digits into digits, most significant first
+func toDigits(value *big.Int, digits []int) {
+	v := new(big.Int).Set(value)
+	base := big.NewInt(int64(len(base62)))
+	digit := new(big.Int)
+	for i := len(digits) - 1; i >= 0; i-- {
+		v.DivMod(v, base, digit)
+		digits[i] = int(digit.Int64())
+	}
+}
+
+// fromDigits sets value to the number written by base62 digits
+func fromDigits(digits []int, value *big.Int) {
+	base := big.NewInt(int64(len(base62)))
+	value.SetInt64(0)
+	for _, digit := range digits {
+		value.Mul(value, base)
+		value.Add(value, big.NewInt(int64(digit)))
+	}
+}
+
+// space returns the number of aliases of length characters
+func space(length int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(int64(len(base62))), big.NewInt(int64(length)), nil)
+}
+
+// shuffle reorders alphabet deterministically by salt, an empty salt keeps it as it is
+func shuffle(alphabet, salt string) string {
+	if salt == "" {
+		return alphabet
+	}
+	buf := []byte(alphabet)
+	for i, v, p := len(buf)-1, 0, 0; i > 0; i, v = i-1, v+1 {
+		v %= len(salt)
+		n := int(salt[v])
+		p += n
+		j := (n + v + p) % i
+		buf[i], buf[j] = buf[j], buf[i]
+	}
+	return string(buf)
+}
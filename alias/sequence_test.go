@@ -0,0 +1,69 @@
+package alias
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSequenceRoundTrip(t *testing.T) {
+	for _, salt := range []string{"", "salt"} {
+		sequence := NewSequence(nil, salt, 8)
+		seen := make(map[string]int64)
+
+		numbers := []int64{math.MaxInt64, math.MaxInt64 - 1, 62*62*62*62*62*62*62*62 - 1, 62 * 62 * 62 * 62 * 62 * 62 * 62 * 62}
+		for n := int64(0); n < 20000; n++ {
+			numbers = append(numbers, n)
+		}
+
+		for _, n := range numbers {
+			alias := sequence.Encode(n)
+			if len(alias) < 8 {
+				t.Fatalf("salt %q: Encode(%d) = %q is shorter than 8 characters", salt, n, alias)
+			}
+			if other, ok := seen[alias]; ok {
+				t.Fatalf("salt %q: %d and %d both encode to %q", salt, other, n, alias)
+			}
+			seen[alias] = n
+
+			got, err := sequence.Decode(alias)
+			if err != nil || got != n {
+				t.Fatalf("salt %q: Decode(Encode(%d) = %q) = %d, %v", salt, n, alias, got, err)
+			}
+		}
+	}
+}
+
+func TestSequenceConsecutiveAliasesAreUnrelated(t *testing.T) {
+	sequence := NewSequence(nil, "salt", 8)
+
+	// unrelated aliases share their first or last two characters about once in 2000 pairs
+	const pairs = 10000
+	shared := 0
+	previous := sequence.Encode(0)
+	for n := int64(1); n <= pairs; n++ {
+		alias := sequence.Encode(n)
+		if alias[:2] == previous[:2] || alias[len(alias)-2:] == previous[len(previous)-2:] {
+			shared++
+		}
+		previous = alias
+	}
+	if shared > pairs/100 {
+		t.Errorf("%d of %d consecutive aliases share their first or last two characters", shared, pairs)
+	}
+}
+
+func TestSequenceDecodeRejectsForeignAliases(t *testing.T) {
+	sequence := NewSequence(nil, "salt", 8)
+
+	for _, alias := range []string{"short", "not-base62", "héllo123"} {
+		if _, err := sequence.Decode(alias); err == nil {
+			t.Errorf("Decode(%q) succeeded", alias)
+		}
+	}
+
+	// a small number only has its shortest encoding
+	long := NewSequence(nil, "salt", 9).Encode(5)
+	if n, err := sequence.Decode(long); err == nil {
+		t.Errorf("Decode(%q) = %d, want an error", long, n)
+	}
+}
@@ -0,0 +1,46 @@
+package alias
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Candidates returns alternatives to a taken alias in order of preference: numbered suffixes,
+// an abbreviation and dated variants. Candidates are not checked against the policy or the database.
+func Candidates(taken string, now time.Time) []string {
+	var candidates []string
+	add := func(candidate string) {
+		if candidate != "" && candidate != taken && !slices.Contains(candidates, candidate) {
+			candidates = append(candidates, candidate)
+		}
+	}
+
+	for n := 2; n <= 4; n++ {
+		add(taken + "-" + strconv.Itoa(n))
+	}
+	add(abbreviate(taken))
+	add(taken + "-" + now.Format("2006"))
+	add(taken + "-" + strings.ToLower(now.Format("Jan06")))
+	add(taken + "-" + now.Format("20060102"))
+	return candidates
+}
+
+// abbreviate drops the vowels of every word but their first letter, summer-sale becomes smmr-sl
+func abbreviate(alias string) string {
+	var b strings.Builder
+	first := true
+	for _, r := range alias {
+		switch {
+		case r == '-' || r == '_':
+			first = true
+		case !first && strings.ContainsRune("aeiouAEIOU", r):
+			continue
+		default:
+			first = false
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
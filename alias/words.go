@@ -0,0 +1,49 @@
+package alias
+
+import (
+	"context"
+	"strings"
+)
+
+var adjectives = []string{
+	"amber", "bold", "brave", "bright", "brisk", "calm", "clever", "cosy",
+	"crisp", "curly", "daring", "eager", "early", "fancy", "fast", "fluffy",
+	"gentle", "giant", "glad", "golden", "grand", "happy", "hasty", "honest",
+	"jolly", "keen", "kind", "lively", "lucky", "mellow", "merry", "mighty",
+	"misty", "modest", "noble", "odd", "proud", "quick", "quiet", "rapid",
+	"rosy", "royal", "rusty", "shiny", "silent", "silver", "sleepy", "smart",
+	"snowy", "solid", "spicy", "steady", "sunny", "super", "swift", "tidy",
+	"tiny", "vivid", "warm", "wavy", "wild", "windy", "wise", "witty",
+}
+
+var nouns = []string{
+	"apple", "badger", "banana", "beacon", "bison", "breeze", "canyon", "cedar",
+	"comet", "coral", "cricket", "dolphin", "falcon", "feather", "forest", "fox",
+	"garden", "gecko", "glacier", "harbor", "hazel", "heron", "island", "jaguar",
+	"koala", "lagoon", "lemon", "lily", "llama", "lotus", "maple", "meadow",
+	"melon", "meteor", "monkey", "nectar", "ocean", "olive", "orbit", "otter",
+	"panda", "parrot", "pebble", "pepper", "planet", "pony", "quartz", "rabbit",
+	"raven", "river", "rocket", "salmon", "sparrow", "spruce", "summit", "tiger",
+	"tulip", "turtle", "valley", "violet", "walrus", "willow", "wombat", "zebra",
+}
+
+// Words creates pronounceable aliases such as swift-calm-otter, Count-1 adjectives followed by a noun
+type Words struct {
+	Count int
+}
+
+func (w Words) Generate(_ context.Context) (string, error) {
+	words := make([]string, w.Count)
+	for i := range words {
+		list := adjectives
+		if i == len(words)-1 {
+			list = nouns
+		}
+		n, err := randomIndex(len(list))
+		if err != nil {
+			return "", err
+		}
+		words[i] = list[n]
+	}
+	return strings.Join(words, "-"), nil
+}
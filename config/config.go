@@ -44,6 +44,30 @@ type ConfigParams struct {
 	Title                  string
 	FontURL                string
 	JWTSecret              string
+	ReaperInterval         time.Duration
+	ReaperMode             string
+	UnlockMaxAttempts      int
+	UnlockLockout          time.Duration
+	BulkWorkers            int
+	BulkMaxItems           int
+	MetadataTimeout        time.Duration
+	MetadataMaxBytes       int64
+	DefaultRedirectType    int
+	RedirectMaxAge         time.Duration
+	GeoIPDatabase          string
+	TrustedProxies         []string
+	NotLiveURL             string
+	DisabledLinkURL        string
+	TrashRetention         time.Duration
+	AliasStrategy          string
+	AliasLength            int
+	AliasWords             int
+	AliasSalt              string
+	AliasMinLength         int
+	AliasMaxLength         int
+	ReservedAliasesFile    string
+	CaseInsensitiveAliases bool
+	QRLogoFile             string
 }
 
 func InitializeConfig() *ConfigParams {
@@ -54,6 +78,22 @@ func InitializeConfig() *ConfigParams {
 	expiration, _ := utils.ConvertStr(Config("expiration"))
 	skip_failed_requests, _ := strconv.ParseBool(Config("skip_failed_requests"))
 	skip_successful_requests, _ := strconv.ParseBool(Config("skip_successful_requests"))
+	reaperInterval, _ := utils.ConvertStr(Config("reaper_interval"))
+	unlockMaxAttempts, _ := utils.ConvertStr(Config("unlock_max_attempts"))
+	unlockLockout, _ := utils.ConvertStr(Config("unlock_lockout"))
+	bulkWorkers, _ := utils.ConvertStr(Config("bulk_workers"))
+	bulkMaxItems, _ := utils.ConvertStr(Config("bulk_max_items"))
+	metadataTimeout, _ := utils.ConvertStr(Config("metadata_timeout"))
+	metadataMaxBytes, _ := utils.ConvertStr(Config("metadata_max_bytes"))
+	defaultRedirectType, _ := utils.ConvertStr(Config("default_redirect_type"))
+	redirectMaxAge, _ := utils.ConvertStr(Config("redirect_max_age"))
+	trustedProxies := utils.SplitList(Config("trusted_proxies"))
+	trashRetentionDays, _ := utils.ConvertStr(Config("trash_retention_days"))
+	aliasLength, _ := utils.ConvertStr(Config("alias_length"))
+	aliasWords, _ := utils.ConvertStr(Config("alias_words"))
+	aliasMinLength, _ := utils.ConvertStr(Config("alias_min_length"))
+	aliasMaxLength, _ := utils.ConvertStr(Config("alias_max_length"))
+	caseInsensitiveAliases, _ := strconv.ParseBool(Config("case_insensitive_aliases"))
 
 	return &ConfigParams{
 		Port:                   Config("PORT"),
@@ -73,5 +113,29 @@ func InitializeConfig() *ConfigParams {
 		Title:                  Config("metrics_title"),
 		FontURL:                Config("metrics_font_URL"),
 		JWTSecret:              Config("jwt_secret"),
+		ReaperInterval:         time.Duration(reaperInterval) * time.Minute,
+		ReaperMode:             Config("reaper_mode"),
+		UnlockMaxAttempts:      unlockMaxAttempts,
+		UnlockLockout:          time.Duration(unlockLockout) * time.Minute,
+		BulkWorkers:            bulkWorkers,
+		BulkMaxItems:           bulkMaxItems,
+		MetadataTimeout:        time.Duration(metadataTimeout) * time.Second,
+		MetadataMaxBytes:       int64(metadataMaxBytes),
+		DefaultRedirectType:    defaultRedirectType,
+		RedirectMaxAge:         time.Duration(redirectMaxAge) * time.Minute,
+		GeoIPDatabase:          Config("geoip_database"),
+		TrustedProxies:         trustedProxies,
+		NotLiveURL:             Config("not_live_url"),
+		DisabledLinkURL:        Config("disabled_link_url"),
+		TrashRetention:         time.Duration(trashRetentionDays) * 24 * time.Hour,
+		AliasStrategy:          Config("alias_strategy"),
+		AliasLength:            aliasLength,
+		AliasWords:             aliasWords,
+		AliasSalt:              Config("alias_salt"),
+		AliasMinLength:         aliasMinLength,
+		AliasMaxLength:         aliasMaxLength,
+		ReservedAliasesFile:    Config("reserved_aliases_file"),
+		CaseInsensitiveAliases: caseInsensitiveAliases,
+		QRLogoFile:             Config("qr_logo_file"),
 	}
 }
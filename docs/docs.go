@@ -59,43 +59,98 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/links/all": {
+        "/api/v1/domains/": {
             "get": {
-                "description": "Returns all links",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns all domains registered by the user",
                 "produces": [
                     "application/json"
                 ],
-                "summary": "Fetch all links",
+                "tags": [
+                    "protected"
+                ],
+                "summary": "Fetch the custom domains of a user",
                 "responses": {
                     "200": {
                         "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "404": {
+                        "description": "Not Found"
                     }
                 }
-            }
-        },
-        "/api/v1/links/shorten": {
+            },
             "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Returns a Short URL",
+                "description": "The domain resolves links from the request Host header once it is verified\nPublish the returned token as a TXT record, then call the verify endpoint",
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
                     "protected"
                 ],
-                "summary": "Insert an entry for a Short URL and Long URL",
+                "summary": "Register a custom domain",
                 "parameters": [
                     {
-                        "description": "Shorten a Link (custom alias is optional)",
-                        "name": "shorten_link",
+                        "description": "Domain to register",
+                        "name": "domain",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/handler.ShortenLinkModel"
+                            "$ref": "#/definitions/handler.DomainModel"
                         }
                     }
                 ],
+                "responses": {
+                    "201": {
+                        "description": "Created"
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "409": {
+                        "description": "Conflict"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/api/v1/domains/{host}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Domains that still have links cannot be removed, links of the domain in the trash are deleted for good",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "protected"
+                ],
+                "summary": "Remove a custom domain",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Domain host",
+                        "name": "host",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
                 "responses": {
                     "200": {
                         "description": "OK"
@@ -103,34 +158,40 @@ const docTemplate = `{
                     "400": {
                         "description": "Bad Request"
                     },
-                    "403": {
-                        "description": "Forbidden"
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "409": {
+                        "description": "Conflict"
                     },
                     "500": {
                         "description": "Internal Server Error"
                     }
                 }
-            },
-            "delete": {
+            }
+        },
+        "/api/v1/domains/{host}/verify": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Returns a success message",
+                "description": "Looks up the TXT record returned on registration, the domain resolves links once it matches",
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
                     "protected"
                 ],
-                "summary": "Delete url data by short url",
+                "summary": "Verify the ownership of a custom domain",
                 "parameters": [
                     {
-                        "description": "Delete a Link",
-                        "name": "url",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/handler.DeleteLinkModel"
-                        }
+                        "type": "string",
+                        "description": "Domain host",
+                        "name": "host",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
@@ -143,47 +204,152 @@ const docTemplate = `{
                     "404": {
                         "description": "Not Found"
                     },
+                    "409": {
+                        "description": "Conflict"
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity"
+                    },
                     "500": {
                         "description": "Internal Server Error"
                     }
                 }
             }
         },
-        "/api/v1/links/userlinks": {
+        "/api/v1/links/all": {
+            "get": {
+                "description": "Returns one page of links and the cursor of the next page",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Fetch all links",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Page size (default 50, max 200)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "created_at (default), click_count or alias",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Created at or after this RFC3339 time or date",
+                        "name": "created_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Created before this RFC3339 time or on or before this date",
+                        "name": "created_to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only links pointing to this host",
+                        "name": "destination_host",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    }
+                }
+            }
+        },
+        "/api/v1/links/availability": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Returns all user links",
+                "description": "Returns the policy violations of an invalid alias, or free alternatives when the alias is taken",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "protected"
                 ],
-                "summary": "Fetch all user links",
+                "summary": "Check whether a custom alias can be used",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Custom alias",
+                        "name": "alias",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Custom domain of the link",
+                        "name": "domain",
+                        "in": "query"
+                    }
+                ],
                 "responses": {
                     "200": {
                         "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
                     }
                 }
             }
         },
-        "/api/v1/users/": {
+        "/api/v1/links/bulk": {
             "post": {
-                "description": "Returns a message",
-                "summary": "Create a user",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Validates and scans the urls concurrently, then creates them in one transaction\nReturns a status per item: created, duplicate_alias, malicious, invalid_scheme, invalid_alias, scan_failed or failed",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "protected"
+                ],
+                "summary": "Shorten many links in one request",
                 "parameters": [
                     {
-                        "description": "create a user",
-                        "name": "user",
+                        "description": "Links to shorten (custom alias is optional)",
+                        "name": "links",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/handler.User"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/handler.BulkLinkItem"
+                            }
                         }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Unique key of the request, safe to retry with",
+                        "name": "Idempotency-Key",
+                        "in": "header"
                     }
                 ],
                 "responses": {
@@ -193,8 +359,8 @@ const docTemplate = `{
                     "400": {
                         "description": "Bad Request"
                     },
-                    "409": {
-                        "description": "Conflict"
+                    "413": {
+                        "description": "Request Entity Too Large"
                     },
                     "500": {
                         "description": "Internal Server Error"
@@ -202,26 +368,79 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/users/delete": {
-            "delete": {
+        "/api/v1/links/export": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Returns a success message",
+                "description": "Returns alias, destination, click count, timestamps and custom domain as CSV or JSON",
+                "produces": [
+                    "application/json",
+                    "text/csv"
+                ],
                 "tags": [
                     "protected"
                 ],
-                "summary": "Delete user",
+                "summary": "Export all user links",
                 "parameters": [
                     {
-                        "description": "Delete a user",
-                        "name": "password",
+                        "type": "string",
+                        "description": "csv or json (default json)",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    }
+                }
+            }
+        },
+        "/api/v1/links/import": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Accepts the export format (text/csv or application/json), alias, destination, expires_at and domain are imported\nRows that have already expired or whose domain is not registered to the user or not verified are skipped\nWith dry_run=true rows are validated and checked for alias conflicts without creating links",
+                "consumes": [
+                    "application/json",
+                    "text/csv"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "protected"
+                ],
+                "summary": "Import links from CSV or JSON",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "Validate without creating links",
+                        "name": "dry_run",
+                        "in": "query"
+                    },
+                    {
+                        "description": "Links to import",
+                        "name": "links",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/handler.PasswordInput"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/handler.LinkRecord"
+                            }
                         }
                     }
                 ],
@@ -232,48 +451,1324 @@ const docTemplate = `{
                     "400": {
                         "description": "Bad Request"
                     },
+                    "413": {
+                        "description": "Request Entity Too Large"
+                    },
                     "500": {
                         "description": "Internal Server Error"
                     }
                 }
             }
         },
-        "/{link}": {
+        "/api/v1/links/search": {
             "get": {
-                "description": "Redirects to the original URL",
-                "summary": "Fetch a Original URL by Short URL",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Full-text search over the alias, page title and description, destination host and path, and notes of the user's links\nResults are ranked by relevance, matching words in headline are wrapped in \u003cmark\u003e tags",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "protected"
+                ],
+                "summary": "Search the links of a user",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Redirects to Original URL",
-                        "name": "link",
-                        "in": "path",
+                        "description": "Search terms, supports quoted phrases, or and -",
+                        "name": "q",
+                        "in": "query",
                         "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size (default 50, max 200)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
                     }
                 ],
                 "responses": {
-                    "301": {
-                        "description": "Moved Permanently"
+                    "200": {
+                        "description": "OK"
                     },
-                    "404": {
-                        "description": "Not Found"
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "handler.DeleteLinkModel": {
-            "description": "Delete Link Model Url",
-            "type": "object",
-            "properties": {
-                "url": {
-                    "type": "string"
-                }
-            }
         },
-        "handler.PasswordInput": {
-            "description": "Shorten link Model Password",
+        "/api/v1/links/shorten": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns a Short URL\nA repeated request with the same Idempotency-Key within 24h gets the first response back",
+                "tags": [
+                    "protected"
+                ],
+                "summary": "Insert an entry for a Short URL and Long URL",
+                "parameters": [
+                    {
+                        "description": "Shorten a Link (custom alias is optional)",
+                        "name": "shorten_link",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.ShortenLinkModel"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Unique key of the request, safe to retry with",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "409": {
+                        "description": "Conflict"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Moves the link to the trash, it can be restored until the trash retention has passed",
+                "tags": [
+                    "protected"
+                ],
+                "summary": "Delete url data by short url",
+                "parameters": [
+                    {
+                        "description": "Delete a Link",
+                        "name": "url",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.DeleteLinkModel"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/api/v1/links/trash": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Deleted links stop redirecting and are removed for good once the trash retention has passed",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "protected"
+                ],
+                "summary": "List the deleted links of a user",
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/api/v1/links/trash/{alias}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Removes the link with its click history, this cannot be undone",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "protected"
+                ],
+                "summary": "Delete a Short URL in the trash for good",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Short URL",
+                        "name": "alias",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Custom domain of the link",
+                        "name": "domain",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/api/v1/links/trash/{alias}/restore": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "The link redirects again with its clicks, tags and revisions intact",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "protected"
+                ],
+                "summary": "Restore a deleted Short URL",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Short URL",
+                        "name": "alias",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Custom domain of the link",
+                        "name": "domain",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/api/v1/links/userlinks": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns one page of user links with their tags and the cursor of the next page",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "protected"
+                ],
+                "summary": "Fetch all user links",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Page size (default 50, max 200)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "created_at (default), click_count or alias",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Created at or after this RFC3339 time or date",
+                        "name": "created_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Created before this RFC3339 time or on or before this date",
+                        "name": "created_to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only links pointing to this host",
+                        "name": "destination_host",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only links with this tag",
+                        "name": "tag",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only links in this folder",
+                        "name": "folder",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    }
+                }
+            }
+        },
+        "/api/v1/links/{alias}": {
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Updates the link and stores the change as a new revision\nFolder, notes and tag changes are not part of the revision history",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "protected"
+                ],
+                "summary": "Edit a Short URL",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Short URL",
+                        "name": "alias",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Fields to change",
+                        "name": "link",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.EditLinkModel"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "409": {
+                        "description": "Conflict"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/api/v1/links/{alias}/geo": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "The country of a visitor is looked up in the configured GeoIP database, rules are skipped when none is configured\nDevice targeting rules are checked before geo rules, empty lists remove the rules or the blocklist",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "protected"
+                ],
+                "summary": "Replace the geo rules and country blocklist of a Short URL",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Short URL",
+                        "name": "alias",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Geo rules and blocklist",
+                        "name": "rules",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.GeoTargetingModel"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/api/v1/links/{alias}/password": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "An empty password removes the protection",
+                "tags": [
+                    "protected"
+                ],
+                "summary": "Set or remove the password of a Short URL",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Short URL",
+                        "name": "alias",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Link password",
+                        "name": "password",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.PasswordInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/api/v1/links/{alias}/qr": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Encodes the public short url, on the custom domain of the link when it has one\nThe url carries ?src=qr, visits through it are counted in qr_scans and the tag is not forwarded\nA logo always uses error correction level H",
+                "produces": [
+                    "image/png",
+                    "image/svg+xml"
+                ],
+                "tags": [
+                    "protected"
+                ],
+                "summary": "Render the QR code of a short URL",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Short link alias",
+                        "name": "alias",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Custom domain of the link",
+                        "name": "domain",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "png (default) or svg",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Width and height in pixels (default 256, 64 to 2048)",
+                        "name": "size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Error correction level L, M (default), Q or H",
+                        "name": "level",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Foreground colour as rrggbb or rrggbbaa (default 000000)",
+                        "name": "fg",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Background colour as rrggbb or rrggbbaa (default ffffff)",
+                        "name": "bg",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Quiet zone in modules (default 4, max 16)",
+                        "name": "margin",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Draw the configured logo over the centre",
+                        "name": "logo",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/api/v1/links/{alias}/revisions": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns all revisions, newest first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "protected"
+                ],
+                "summary": "Fetch the revision history of a Short URL",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Short URL",
+                        "name": "alias",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    }
+                }
+            }
+        },
+        "/api/v1/links/{alias}/revisions/{revision}/rollback": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Restores the revision and records the rollback as a new revision\nA restored alias must still follow the alias policy and a restored url is scanned again",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "protected"
+                ],
+                "summary": "Roll a Short URL back to an earlier revision",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Short URL",
+                        "name": "alias",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Revision number",
+                        "name": "revision",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "409": {
+                        "description": "Conflict"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/api/v1/links/{alias}/schedule": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Windows are checked in order after the targeting rules, a window ending before it starts runs past midnight\nAn empty list removes the schedule",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "protected"
+                ],
+                "summary": "Replace the weekly schedule of a Short URL",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Short URL",
+                        "name": "alias",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Weekly schedule",
+                        "name": "schedule",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.ScheduleModel"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/api/v1/links/{alias}/targeting": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Rules match on os (ios, android, windows, macos, linux, chromeos, other), device (mobile, tablet, desktop) and browser\nEvery destination is scanned like the link's url, an empty list removes all rules",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "protected"
+                ],
+                "summary": "Replace the targeting rules of a Short URL",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Short URL",
+                        "name": "alias",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Targeting rules",
+                        "name": "rules",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.TargetingModel"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/api/v1/links/{alias}/variants": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns every variant with its expected traffic share and its actual clicks and click share",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "protected"
+                ],
+                "summary": "Compare the variants of a Short URL",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Short URL",
+                        "name": "alias",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Visitors not matched by a targeting rule are spread over the variants by weight, an empty list ends the split test\nClicks of variants that are kept are preserved, clicks of removed variants are deleted",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "protected"
+                ],
+                "summary": "Replace the split test variants of a Short URL",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Short URL",
+                        "name": "alias",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Split test variants",
+                        "name": "variants",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.VariantsModel"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/api/v1/tags/": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns all tags with the number of links using them",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "protected"
+                ],
+                "summary": "Fetch the tags of a user",
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    }
+                }
+            }
+        },
+        "/api/v1/tags/{name}": {
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Renames the tag on all links, use merge when the new name is already a tag",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "protected"
+                ],
+                "summary": "Rename a tag",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Tag name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New name",
+                        "name": "tag",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.TagModel"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "409": {
+                        "description": "Conflict"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/api/v1/tags/{name}/merge": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Moves all links of the tag to the target tag and removes the merged tag",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "protected"
+                ],
+                "summary": "Merge a tag into another tag",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Tag to merge",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Target tag",
+                        "name": "tag",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.MergeTagModel"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/api/v1/users/": {
+            "post": {
+                "description": "Returns a message",
+                "summary": "Create a user",
+                "parameters": [
+                    {
+                        "description": "create a user",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.User"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "409": {
+                        "description": "Conflict"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/api/v1/users/delete": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns a success message",
+                "tags": [
+                    "protected"
+                ],
+                "summary": "Delete user",
+                "parameters": [
+                    {
+                        "description": "Delete a user",
+                        "name": "password",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.PasswordInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/{link}": {
+            "get": {
+                "description": "Redirects to the original URL, password protected links serve an unlock form instead\nUses the redirect type of the link or the server default, permanent redirects may be cached by browsers for redirect_max_age",
+                "summary": "Fetch a Original URL by Short URL",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Redirects to Original URL",
+                        "name": "link",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "301": {
+                        "description": "Moved Permanently"
+                    },
+                    "302": {
+                        "description": "Found"
+                    },
+                    "307": {
+                        "description": "Temporary Redirect"
+                    },
+                    "308": {
+                        "description": "Permanent Redirect"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "410": {
+                        "description": "Gone"
+                    },
+                    "451": {
+                        "description": "Unavailable For Legal Reasons"
+                    }
+                }
+            },
+            "post": {
+                "description": "Redirects to the original URL when the password is correct",
+                "consumes": [
+                    "application/x-www-form-urlencoded"
+                ],
+                "produces": [
+                    "text/html"
+                ],
+                "summary": "Unlock a password protected Short URL",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Short URL",
+                        "name": "link",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Link password",
+                        "name": "password",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "303": {
+                        "description": "See Other"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "410": {
+                        "description": "Gone"
+                    },
+                    "429": {
+                        "description": "Too Many Requests"
+                    },
+                    "451": {
+                        "description": "Unavailable For Legal Reasons"
+                    }
+                }
+            }
+        },
+        "/{link}+": {
+            "get": {
+                "description": "Renders the destination, scan verdict, creation date and click count, format=json returns them as JSON\nPreviews are not counted as clicks",
+                "produces": [
+                    "text/html",
+                    "application/json"
+                ],
+                "summary": "Show where a Short URL leads without following it",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Short URL",
+                        "name": "link",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "html (default) or json",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "410": {
+                        "description": "Gone"
+                    },
+                    "451": {
+                        "description": "Unavailable For Legal Reasons"
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "handler.BulkLinkItem": {
+            "description": "Bulk link item Model Url, Custom_alias",
+            "type": "object",
+            "properties": {
+                "custom_alias": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.DeleteLinkModel": {
+            "description": "Delete Link Model Url, Domain",
+            "type": "object",
+            "properties": {
+                "domain": {
+                    "description": "Custom domain of the link, empty for the default domain",
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.DomainModel": {
+            "description": "Domain Model Host",
+            "type": "object",
+            "properties": {
+                "host": {
+                    "description": "Host name without scheme or port, e.g. go.example.com",
+                    "type": "string"
+                }
+            }
+        },
+        "handler.EditLinkModel": {
+            "description": "Edit link Model Url, Alias, Redirect_type, Enabled, Activate_at, Forward_query, Utm, Folder, Notes, Tags (omitted fields are left unchanged)",
+            "type": "object",
+            "properties": {
+                "activate_at": {
+                    "description": "RFC3339 time or duration from now, an empty value makes the link live immediately",
+                    "type": "string"
+                },
+                "alias": {
+                    "type": "string"
+                },
+                "enabled": {
+                    "description": "Paused links keep their data but stop redirecting",
+                    "type": "boolean"
+                },
+                "folder": {
+                    "description": "An empty folder removes the link from its folder",
+                    "type": "string"
+                },
+                "forward_query": {
+                    "description": "Pass the query string of the short url on to the destination",
+                    "type": "boolean"
+                },
+                "notes": {
+                    "description": "Free text used by search, empty notes are removed",
+                    "type": "string"
+                },
+                "redirect_type": {
+                    "description": "One of 301, 302, 307 or 308",
+                    "type": "integer"
+                },
+                "tags": {
+                    "description": "Replaces all tags, an empty list removes them",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "url": {
+                    "type": "string"
+                },
+                "utm": {
+                    "description": "Replaces all UTM parameters, empty fields are removed",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/handler.UTMModel"
+                        }
+                    ]
+                }
+            }
+        },
+        "handler.GeoTargetingModel": {
+            "description": "Ordered country rules and countries the link is blocked in Visitors from a blocked country get 451, visitors matching no rule are sent to the link's url",
+            "type": "object",
+            "properties": {
+                "blocked_countries": {
+                    "description": "ISO 3166-1 alpha-2 country codes",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "rules": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/targeting.GeoRule"
+                    }
+                }
+            }
+        },
+        "handler.LinkRecord": {
+            "description": "Link Record Model used by export and import Alias, Destination, Click_count, Created_at, Updated_at, Expires_at, Domain",
+            "type": "object",
+            "properties": {
+                "alias": {
+                    "type": "string"
+                },
+                "click_count": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "destination": {
+                    "type": "string"
+                },
+                "domain": {
+                    "description": "Custom domain of the link, empty for the default domain",
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.MergeTagModel": {
+            "description": "Merge Tag Model Into",
+            "type": "object",
+            "properties": {
+                "into": {
+                    "description": "Tag that receives the links, it is created if it does not exist",
+                    "type": "string"
+                }
+            }
+        },
+        "handler.PasswordInput": {
+            "description": "Shorten link Model Password",
             "type": "object",
             "properties": {
                 "password": {
@@ -281,15 +1776,129 @@ const docTemplate = `{
                 }
             }
         },
+        "handler.ScheduleModel": {
+            "description": "Weekly destination windows in an IANA timezone such as Europe/Berlin (default UTC) Visitors outside every window are sent to the link's url",
+            "type": "object",
+            "properties": {
+                "timezone": {
+                    "type": "string"
+                },
+                "windows": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/targeting.Window"
+                    }
+                }
+            }
+        },
         "handler.ShortenLinkModel": {
-            "description": "Shorten link Model Url, Custom_alias",
+            "description": "Shorten link Model Url, Custom_alias, Alias_strategy, Expires_at, Activate_at, Max_clicks, Password, Redirect_type, Forward_query, Utm, Reuse_existing, Domain, Folder, Notes, Tags",
             "type": "object",
             "properties": {
+                "activate_at": {
+                    "description": "The link only starts redirecting at this RFC3339 time or duration from now",
+                    "type": "string"
+                },
+                "alias_strategy": {
+                    "description": "One of random, sequence or words, the server default is used when omitted",
+                    "type": "string"
+                },
                 "custom_alias": {
+                    "description": "Letters of any script, digits, emoji, - and _ within the configured length, reserved words, profanity\nand look-alike letters mixing scripts are rejected",
+                    "type": "string"
+                },
+                "domain": {
+                    "description": "Verified custom domain of the user, empty for the default domain",
+                    "type": "string"
+                },
+                "expires_at": {
+                    "description": "RFC3339 time (2025-12-31T23:59:59Z) or a duration from now (72h)",
+                    "type": "string"
+                },
+                "folder": {
+                    "type": "string"
+                },
+                "forward_query": {
+                    "description": "Pass the query string of the short url on to the destination",
+                    "type": "boolean"
+                },
+                "max_clicks": {
+                    "description": "Stop redirecting after this many clicks, 1 creates a one-time link",
+                    "type": "integer"
+                },
+                "notes": {
+                    "type": "string"
+                },
+                "password": {
+                    "description": "Visitors must enter this password before being redirected",
                     "type": "string"
                 },
+                "redirect_type": {
+                    "description": "One of 301, 302, 307 or 308, the server default is used when omitted",
+                    "type": "integer"
+                },
+                "reuse_existing": {
+                    "description": "Return the newest live link already pointing at the same url and without any option instead of creating\na new one, ignored when any other option than the domain or alias strategy is given",
+                    "type": "boolean"
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
                 "url": {
                     "type": "string"
+                },
+                "utm": {
+                    "description": "Added to the destination on every redirect, overriding parameters of the same name",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/handler.UTMModel"
+                        }
+                    ]
+                }
+            }
+        },
+        "handler.TagModel": {
+            "description": "Tag Model Name",
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.TargetingModel": {
+            "description": "Ordered targeting rules, the first rule matching the visitor's User-Agent wins Visitors matching no rule are sent to the link's url",
+            "type": "object",
+            "properties": {
+                "rules": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/targeting.Rule"
+                    }
+                }
+            }
+        },
+        "handler.UTMModel": {
+            "description": "UTM parameters appended to the destination on every redirect Source, Medium, Campaign, Term, Content",
+            "type": "object",
+            "properties": {
+                "campaign": {
+                    "type": "string"
+                },
+                "content": {
+                    "type": "string"
+                },
+                "medium": {
+                    "type": "string"
+                },
+                "source": {
+                    "type": "string"
+                },
+                "term": {
+                    "type": "string"
                 }
             }
         },
@@ -318,6 +1927,93 @@ const docTemplate = `{
                     "type": "string"
                 }
             }
+        },
+        "handler.VariantsModel": {
+            "description": "Weighted destinations of a split test, e.g. weights 70 and 30 Sticky visitors keep seeing the same variant through a cookie",
+            "type": "object",
+            "properties": {
+                "sticky": {
+                    "type": "boolean"
+                },
+                "variants": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/targeting.Variant"
+                    }
+                }
+            }
+        },
+        "targeting.GeoRule": {
+            "type": "object",
+            "properties": {
+                "countries": {
+                    "description": "ISO 3166-1 alpha-2 country codes such as DE or US",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "targeting.Rule": {
+            "type": "object",
+            "properties": {
+                "browser": {
+                    "description": "Browser name such as chrome, safari, firefox, edge or opera",
+                    "type": "string"
+                },
+                "device": {
+                    "description": "One of mobile, tablet or desktop",
+                    "type": "string"
+                },
+                "os": {
+                    "description": "One of ios, android, windows, macos, linux, chromeos or other",
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "targeting.Variant": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                },
+                "weight": {
+                    "type": "integer"
+                }
+            }
+        },
+        "targeting.Window": {
+            "type": "object",
+            "properties": {
+                "days": {
+                    "description": "Days of the week: mon, tue, wed, thu, fri, sat or sun, empty means every day",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "end": {
+                    "description": "24 hour clock time such as 17:30, the window ends just before it",
+                    "type": "string"
+                },
+                "start": {
+                    "description": "24 hour clock time such as 09:00",
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
         }
     }
 }`
@@ -0,0 +1,61 @@
+package geoip
+
+import (
+	"net"
+
+	"github.com/oschwald/maxminddb-golang"
+)
+
+// Reader looks up countries in a MaxMind format database, both Country and City databases work
+type Reader struct {
+	db *maxminddb.Reader
+}
+
+// record is the part of a GeoIP2/GeoLite2 record needed to find the country
+type record struct {
+	Country struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"country"`
+	RegisteredCountry struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"registered_country"`
+}
+
+// Open memory maps the .mmdb file at path
+func Open(path string) (*Reader, error) {
+	db, err := maxminddb.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	return &Reader{db: db}, nil
+}
+
+// Close unmaps the database
+func (r *Reader) Close() error {
+	if r == nil {
+		return nil
+	}
+	return r.db.Close()
+}
+
+// Country returns the ISO 3166-1 alpha-2 code of the country of ip, empty when it is unknown.
+// A nil Reader knows no countries so geo rules are skipped when no database is configured.
+func (r *Reader) Country(ip string) string {
+	if r == nil {
+		return ""
+	}
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return ""
+	}
+
+	var rec record
+	if err := r.db.Lookup(parsed, &rec); err != nil {
+		return ""
+	}
+	// addresses without a known location, such as anycast ranges, only have the registered country
+	if rec.Country.ISOCode != "" {
+		return rec.Country.ISOCode
+	}
+	return rec.RegisteredCountry.ISOCode
+}
@@ -10,6 +10,7 @@ import (
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
 
 	"github.com/tin3ga/shortly/config"
@@ -71,6 +72,15 @@ func GetUserIDFromClaims(c *fiber.Ctx, authHeader string) (string, error) {
 
 }
 
+// getUserID returns the ID of the authenticated user making the request
+func getUserID(c *fiber.Ctx) (uuid.UUID, error) {
+	userIDString, err := GetUserIDFromClaims(c, c.Get("Authorization"))
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return uuid.Parse(userIDString)
+}
+
 func GenerateToken(userModel database.User, jwtsecret string) (string, error) {
 	token := jwt.New(jwt.SigningMethodHS256)
 	claims := token.Claims.(jwt.MapClaims)
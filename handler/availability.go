@@ -0,0 +1,149 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"log"
+	"slices"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"github.com/tin3ga/shortly/alias"
+	"github.com/tin3ga/shortly/internal/database"
+)
+
+// maxSuggestions is how many free alternatives are offered for a taken alias
+const maxSuggestions = 5
+
+// aliasCandidates returns the alternatives to a taken alias that the policy allows
+func aliasCandidates(policy *alias.Policy, taken string) []string {
+	var candidates []string
+	for _, candidate := range alias.Candidates(taken, time.Now()) {
+		if policy.Check(candidate) == nil {
+			candidates = append(candidates, candidate)
+		}
+	}
+	return candidates
+}
+
+// usedAliases reports which aliases are in use on the domain, deleted links keep their alias until they are purged.
+// When the policy ignores case an alias is in use when any link shares its key.
+func usedAliases(ctx context.Context, queries *database.Queries, policy *alias.Policy, domainID uuid.NullUUID, aliases []string) (map[string]bool, error) {
+	used := make(map[string]bool, len(aliases))
+	if len(aliases) == 0 {
+		return used, nil
+	}
+
+	if policy.CaseInsensitive {
+		keys := make([]string, len(aliases))
+		for i, custom := range aliases {
+			keys[i] = alias.Key(custom)
+		}
+		existing, err := queries.GetExistingAliasKeys(ctx, database.GetExistingAliasKeysParams{AliasKeys: keys, DomainID: domainID})
+		if err != nil {
+			return nil, err
+		}
+		for i, custom := range aliases {
+			used[custom] = slices.Contains(existing, keys[i])
+		}
+		return used, nil
+	}
+
+	existing, err := queries.GetExistingAliases(ctx, database.GetExistingAliasesParams{Aliases: aliases, DomainID: domainID})
+	if err != nil {
+		return nil, err
+	}
+	for _, custom := range existing {
+		used[custom] = true
+	}
+	return used, nil
+}
+
+// keyInUse reports whether another link shares the key of an alias when the policy ignores case,
+// exact duplicates are left to the unique index
+func keyInUse(ctx context.Context, queries *database.Queries, policy *alias.Policy, domainID uuid.NullUUID, custom string) (bool, error) {
+	if !policy.CaseInsensitive {
+		return false, nil
+	}
+	used, err := usedAliases(ctx, queries, policy, domainID, []string{custom})
+	return used[custom], err
+}
+
+// freeAliases returns at most maxSuggestions of the candidates that are not used
+func freeAliases(candidates []string, used map[string]bool) []string {
+	free := make([]string, 0, maxSuggestions)
+	for _, candidate := range candidates {
+		if len(free) == maxSuggestions {
+			break
+		}
+		if !used[candidate] {
+			free = append(free, candidate)
+		}
+	}
+	return free
+}
+
+// suggestAliases returns free alternatives to a taken alias, checked against the database in one query
+func suggestAliases(ctx context.Context, queries *database.Queries, policy *alias.Policy, domainID uuid.NullUUID, taken string) []string {
+	candidates := aliasCandidates(policy, taken)
+	used, err := usedAliases(ctx, queries, policy, domainID, candidates)
+	if err != nil {
+		log.Print(err)
+		return []string{}
+	}
+	return freeAliases(candidates, used)
+}
+
+// aliasTaken answers a custom alias that is already used with a 409 and free alternatives
+func aliasTaken(c *fiber.Ctx, ctx context.Context, queries *database.Queries, policy *alias.Policy, domainID uuid.NullUUID, message, taken string) error {
+	suggestions := suggestAliases(ctx, queries, policy, domainID, taken)
+	return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": message, "alias": taken, "suggestions": suggestions})
+}
+
+// checkAliasAvailability Check whether a custom alias can be used
+//
+//	@Summary		Check whether a custom alias can be used
+//	@Description	Returns the policy violations of an invalid alias, or free alternatives when the alias is taken
+//	@Param			alias	query	string	true	"Custom alias"
+//	@Param			domain	query	string	false	"Custom domain of the link"
+//	@Tags			protected
+//	@Security		BearerAuth
+//	@Produce		json
+//	@Success		200
+//	@Failure		400
+//	@Failure		404
+//	@Failure		500
+//	@Router			/api/v1/links/availability [get]
+func CheckAliasAvailability(c *fiber.Ctx, queries *database.Queries, ctx context.Context, policy *alias.Policy) error {
+	custom := alias.Normalize(c.Query("alias"))
+	if custom == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "alias is required"})
+	}
+
+	domainID, err := ownedDomainID(c, queries, ctx, c.Query("domain"))
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	if err := policy.Check(custom); err != nil {
+		var policyErr *alias.PolicyError
+		if errors.As(err, &policyErr) {
+			return c.JSON(fiber.Map{"alias": custom, "available": false, "violations": policyErr.Violations})
+		}
+		return errorResponse(c, err)
+	}
+
+	candidates := aliasCandidates(policy, custom)
+	used, err := usedAliases(ctx, queries, policy, domainID, append([]string{custom}, candidates...))
+	if err != nil {
+		log.Print(err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Cannot check alias"})
+	}
+
+	if !used[custom] {
+		return c.JSON(fiber.Map{"alias": custom, "available": true})
+	}
+	return c.JSON(fiber.Map{"alias": custom, "available": false, "suggestions": freeAliases(candidates, used)})
+}
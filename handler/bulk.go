@@ -0,0 +1,334 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"github.com/tin3ga/shortly/alias"
+	"github.com/tin3ga/shortly/internal/database"
+)
+
+const (
+	defaultBulkWorkers  = 5
+	defaultBulkMaxItems = 500
+)
+
+// Per item results of a bulk request
+const (
+	BulkStatusCreated          = "created"
+	BulkStatusDuplicateAlias   = "duplicate_alias"
+	BulkStatusMalicious        = "malicious"
+	BulkStatusInvalidScheme    = "invalid_scheme"
+	BulkStatusInvalidAlias     = "invalid_alias"
+	BulkStatusScanFailed       = "scan_failed"
+	BulkStatusFailed           = "failed"
+	BulkStatusValid            = "valid"             // dry run imports only
+	BulkStatusExpired          = "expired"           // imports only
+	BulkStatusUnknownDomain    = "unknown_domain"    // imports only
+	BulkStatusUnverifiedDomain = "unverified_domain" // imports only
+)
+
+// Bulk Link Item model info
+//
+//	@Description	Bulk link item Model
+//	@Description	Url, Custom_alias
+type BulkLinkItem struct {
+	Url          string `json:"url"`
+	Custom_alias string `json:"custom_alias"`
+	// kept from the import file, a domain that is not registered to the user leaves domainID unset
+	expiresAt  sql.NullTime
+	domain     string
+	domainID   uuid.NullUUID
+	unverified bool
+}
+
+// BulkLinkResult is the outcome of a single bulk item
+type BulkLinkResult struct {
+	Index        int    `json:"index"`
+	Url          string `json:"url"`
+	Custom_alias string `json:"custom_alias,omitempty"`
+	Status       string `json:"status"`
+	ShortLink    string `json:"short_link,omitempty"`
+	Domain       string `json:"domain,omitempty"`
+	Error        string `json:"error,omitempty"`
+	// Rules a rejected custom alias breaks
+	Violations []alias.Violation `json:"violations,omitempty"`
+	verdict    string
+	expiresAt  sql.NullTime
+	domainID   uuid.NullUUID
+	unverified bool
+}
+
+// scanBulkItems validates and scans every item using at most workers concurrent urlscan calls
+func scanBulkItems(items []BulkLinkItem, policy *alias.Policy, apiKey string, workers int) []BulkLinkResult {
+	results := make([]BulkLinkResult, len(items))
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = scanBulkItem(i, items[i], policy, apiKey)
+			}
+		}()
+	}
+
+	for i := range items {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	return results
+}
+
+func scanBulkItem(index int, item BulkLinkItem, policy *alias.Policy, apiKey string) BulkLinkResult {
+	result := newBulkLinkResult(index, item)
+
+	if !result.checkDomain() {
+		return result
+	}
+
+	if item.expiresAt.Valid && !item.expiresAt.Time.After(time.Now()) {
+		result.Status = BulkStatusExpired
+		result.Error = "Link has already expired"
+		return result
+	}
+
+	if !hasValidScheme(item.Url) {
+		result.Status = BulkStatusInvalidScheme
+		result.Error = "URL must start with https://"
+		return result
+	}
+
+	if err := validateAlias(policy, result.Custom_alias); err != nil {
+		result.setInvalidAlias(err)
+		return result
+	}
+
+	verdict, err := scanLink(apiKey, item.Url)
+	if err != nil {
+		var fiberErr *fiber.Error
+		result.Status = BulkStatusScanFailed
+		if errors.As(err, &fiberErr) {
+			if fiberErr.Code == fiber.StatusForbidden {
+				result.Status = BulkStatusMalicious
+			}
+			result.Error = fiberErr.Message
+		}
+		return result
+	}
+
+	result.verdict = verdict
+	return result
+}
+
+func newBulkLinkResult(index int, item BulkLinkItem) BulkLinkResult {
+	return BulkLinkResult{
+		Index:        index,
+		Url:          item.Url,
+		Custom_alias: alias.Normalize(item.Custom_alias),
+		Domain:       item.domain,
+		expiresAt:    item.expiresAt,
+		domainID:     item.domainID,
+		unverified:   item.unverified,
+	}
+}
+
+// checkDomain records a domain of an imported link that links cannot be added to, it reports whether the
+// domain can be used
+func (r *BulkLinkResult) checkDomain() bool {
+	switch {
+	case r.unverified:
+		r.Status = BulkStatusUnverifiedDomain
+		r.Error = unverifiedDomainMessage
+	case r.Domain != "" && !r.domainID.Valid:
+		r.Status = BulkStatusUnknownDomain
+		r.Error = "Domain is not registered to this user"
+	default:
+		return true
+	}
+	return false
+}
+
+// setInvalidAlias records an alias rejected by the alias policy
+func (r *BulkLinkResult) setInvalidAlias(err error) {
+	r.Status = BulkStatusInvalidAlias
+	r.Error = err.Error()
+
+	var policyErr *alias.PolicyError
+	if errors.As(err, &policyErr) {
+		r.Violations = policyErr.Violations
+	}
+}
+
+// insertBulkItems creates every scanned item in one transaction, each insert runs in its own
+// savepoint so a duplicate alias only fails that item
+func insertBulkItems(ctx context.Context, db *sql.DB, queries *database.Queries, generator alias.Generator, policy *alias.Policy, userID uuid.UUID, results []BulkLinkResult) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	qtx := queries.WithTx(tx)
+
+	for i := range results {
+		result := &results[i]
+		if result.Status != "" {
+			continue
+		}
+
+		if err := insertBulkItem(ctx, tx, qtx, generator, policy, userID, result); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// insertBulkItem creates a single item and records its status, generated aliases that are already taken
+// are replaced by a new one at most alias.MaxAttempts times
+func insertBulkItem(ctx context.Context, tx *sql.Tx, qtx *database.Queries, generator alias.Generator, policy *alias.Policy, userID uuid.UUID, result *BulkLinkResult) error {
+	for attempt := 1; ; attempt++ {
+		shortLink := result.Custom_alias
+		if shortLink == "" {
+			var err error
+			if shortLink, err = generator.Generate(ctx); err != nil {
+				return err
+			}
+		}
+
+		// aliases only differing in case are caught before the insert when the policy ignores case
+		inUse, err := keyInUse(ctx, qtx, policy, result.domainID, shortLink)
+		if err != nil {
+			return err
+		}
+
+		if !inUse {
+			if _, err := tx.ExecContext(ctx, "SAVEPOINT bulk_item"); err != nil {
+				return err
+			}
+
+			_, err = qtx.CreateShortLink(ctx, database.CreateShortLinkParams{
+				ID:             uuid.New(),
+				UserID:         userID,
+				ShortLink:      shortLink,
+				LongLink:       result.Url,
+				ExpiresAt:      result.expiresAt,
+				DomainID:       result.domainID,
+				ScanVerdict:    nullString(result.verdict),
+				NormalizedLink: nullString(normalizeLink(result.Url)),
+				AliasKey:       alias.Key(shortLink),
+				AliasKeyUnique: policy.CaseInsensitive,
+			})
+			if err != nil {
+				if _, rollbackErr := tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT bulk_item"); rollbackErr != nil {
+					return rollbackErr
+				}
+			}
+		}
+		if inUse || err != nil {
+			duplicate := inUse || isDuplicateAlias(err)
+			if duplicate && result.Custom_alias == "" && attempt < alias.MaxAttempts {
+				continue
+			}
+			if duplicate {
+				result.Status = BulkStatusDuplicateAlias
+				result.Error = "Duplicate short link, create a new alias"
+			} else {
+				log.Print(err)
+				result.Status = BulkStatusFailed
+				result.Error = "Cannot create short link"
+			}
+			return nil
+		}
+
+		if _, err := tx.ExecContext(ctx, "RELEASE SAVEPOINT bulk_item"); err != nil {
+			return err
+		}
+		result.Status = BulkStatusCreated
+		result.ShortLink = shortLink
+		return nil
+	}
+}
+
+func countCreated(results []BulkLinkResult) int {
+	created := 0
+	for _, result := range results {
+		if result.Status == BulkStatusCreated {
+			created++
+		}
+	}
+	return created
+}
+
+// bulkShortenLinks Shorten many links in one request
+//
+//	@Summary		Shorten many links in one request
+//	@Description	Validates and scans the urls concurrently, then creates them in one transaction
+//	@Description	Returns a status per item: created, duplicate_alias, malicious, invalid_scheme, invalid_alias, scan_failed or failed
+//	@Param			links			body	[]BulkLinkItem	true	"Links to shorten (custom alias is optional)"
+//	@Param			Idempotency-Key	header	string			false	"Unique key of the request, safe to retry with"
+//	@Tags			protected
+//	@Security		BearerAuth
+//	@Produce		json
+//	@Success		200
+//	@Failure		400
+//	@Failure		413
+//	@Failure		500
+//	@Router			/api/v1/links/bulk [post]
+func BulkShortenLinks(c *fiber.Ctx, db *sql.DB, queries *database.Queries, ctx context.Context, apiKey string, workers int, maxItems int, aliases *alias.Generators, policy *alias.Policy) error {
+	var items []BulkLinkItem
+
+	if err := c.BodyParser(&items); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Cannot parse JSON"})
+	}
+
+	if workers <= 0 {
+		workers = defaultBulkWorkers
+	}
+	if maxItems <= 0 {
+		maxItems = defaultBulkMaxItems
+	}
+
+	if len(items) == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "No links provided"})
+	}
+	if len(items) > maxItems {
+		return c.Status(fiber.StatusRequestEntityTooLarge).JSON(fiber.Map{"error": "Too many links in one request", "max_items": maxItems})
+	}
+
+	userID, err := getUserID(c)
+	if err != nil {
+		log.Printf("Error parsing UserID: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"success": false, "message": "Invalid UserID format"})
+	}
+
+	generator, err := aliases.Get("")
+	if err != nil {
+		log.Print(err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Cannot create short links"})
+	}
+
+	results := scanBulkItems(items, policy, apiKey, workers)
+
+	if err := insertBulkItems(ctx, db, queries, generator, policy, userID, results); err != nil {
+		log.Print(err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Cannot create short links"})
+	}
+
+	created := countCreated(results)
+	log.Printf("Bulk shortened %v of %v links", created, len(results))
+
+	return c.JSON(fiber.Map{"created": created, "failed": len(results) - created, "results": results})
+}
@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"net"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ClientIP returns the address of the visitor. Behind trusted proxies it is the rightmost X-Forwarded-For entry
+// that is not a trusted proxy, entries left of it are sent by the client and can be anything.
+// Requests that do not come from a trusted proxy use the address of the connection.
+func ClientIP(c *fiber.Ctx) string {
+	remote := c.Context().RemoteIP().String()
+
+	trusted := c.App().Config().TrustedProxies
+	if len(trusted) == 0 || !isTrustedProxy(remote, trusted) {
+		return remote
+	}
+
+	ips := c.IPs()
+	for i := len(ips) - 1; i >= 0; i-- {
+		ip := strings.TrimSpace(ips[i])
+		if net.ParseIP(ip) == nil {
+			continue
+		}
+		if !isTrustedProxy(ip, trusted) {
+			return ip
+		}
+	}
+	return remote
+}
+
+// isTrustedProxy reports whether ip is one of the trusted proxies, given as addresses or CIDR ranges
+func isTrustedProxy(ip string, trusted []string) bool {
+	addr := net.ParseIP(ip)
+	if addr == nil {
+		return false
+	}
+
+	for _, proxy := range trusted {
+		if strings.Contains(proxy, "/") {
+			if _, network, err := net.ParseCIDR(proxy); err == nil && network.Contains(addr) {
+				return true
+			}
+		} else if proxyAddr := net.ParseIP(proxy); proxyAddr != nil && proxyAddr.Equal(addr) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/url"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/tin3ga/shortly/internal/database"
+)
+
+// normalizeLink returns the form of a destination used to find duplicates: the scheme and host are lowercased,
+// default ports dropped and the query sorted. Links that cannot be parsed are returned as they are.
+func normalizeLink(raw string) string {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || u.Host == "" {
+		return raw
+	}
+
+	u.Scheme = strings.ToLower(u.Scheme)
+	host := strings.ToLower(u.Hostname())
+	if port := u.Port(); port != "" && !(u.Scheme == "https" && port == "443") && !(u.Scheme == "http" && port == "80") {
+		host += ":" + port
+	}
+	u.Host = host
+
+	if u.Path == "" {
+		u.Path = "/"
+	}
+	u.RawQuery = u.Query().Encode()
+	return u.String()
+}
+
+// canReuse reports whether a shorten request may get an existing link, only requests without any link
+// option can, an existing link would not carry the options of the request
+func canReuse(url *ShortenLinkModel) bool {
+	return url.Reuse_existing && url.Custom_alias == "" && url.Password == "" && url.Expires_at == "" &&
+		url.Activate_at == "" && url.Max_clicks == 0 && url.Redirect_type == 0 && !url.Forward_query &&
+		url.Utm == (UTMModel{}) && url.Folder == "" && url.Notes == "" && len(url.Tags) == 0
+}
+
+// findReusableLink returns the newest live link of the user pointing at the same normalized destination
+func findReusableLink(ctx context.Context, queries *database.Queries, userID uuid.UUID, link string, domainID uuid.NullUUID) (database.Shortly, bool, error) {
+	params := database.FindReusableLinkParams{
+		UserID:         userID,
+		NormalizedLink: nullString(normalizeLink(link)),
+		DomainID:       domainID,
+	}
+	data, err := queries.FindReusableLink(ctx, params)
+	if errors.Is(err, sql.ErrNoRows) {
+		return database.Shortly{}, false, nil
+	}
+	if err != nil {
+		return database.Shortly{}, false, err
+	}
+	return data, true, nil
+}
@@ -0,0 +1,49 @@
+package handler
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestFindReusableLinkSkipsLinksWithOptions(t *testing.T) {
+	db, queries := openTestDB(t)
+	ctx := context.Background()
+	userID := uuid.MustParse(seedUserID)
+	link := "https://example.com/page"
+
+	options := []struct {
+		name   string
+		update string
+	}{
+		{"targeting rules", `UPDATE shortly SET targeting_rules = '[{"device":"mobile","url":"https://m.example.com"}]'`},
+		{"geo rules", `UPDATE shortly SET geo_rules = '[{"countries":["DE"],"url":"https://example.de"}]'`},
+		{"blocked countries", `UPDATE shortly SET blocked_countries = '{DE}'`},
+		{"variants", `UPDATE shortly SET variants = '[{"url":"https://example.com/b","weight":50}]'`},
+		{"schedule", `UPDATE shortly SET schedule = '[{"days":["mon"],"start":"09:00","end":"17:00"}]'`},
+	}
+
+	for _, option := range options {
+		t.Run(option.name, func(t *testing.T) {
+			id := uuid.New()
+			_, err := db.Exec(`INSERT INTO shortly(id, user_id, short_link, long_link, normalized_link, alias_key)
+				VALUES($1, $2, $3, $4, $5, $3)`, id, userID, "dedupe"+id.String()[:8], link, normalizeLink(link))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer db.Exec(`DELETE FROM shortly WHERE id = $1`, id)
+
+			if _, found, err := findReusableLink(ctx, queries, userID, link, uuid.NullUUID{}); err != nil || !found {
+				t.Fatalf("link without options: found %v, err %v", found, err)
+			}
+
+			if _, err := db.Exec(option.update+` WHERE id = $1`, id); err != nil {
+				t.Fatal(err)
+			}
+			if data, found, err := findReusableLink(ctx, queries, userID, link, uuid.NullUUID{}); err != nil || found {
+				t.Errorf("link with %v was reused: %v, err %v", option.name, data.ShortLink, err)
+			}
+		})
+	}
+}
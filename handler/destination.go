@@ -0,0 +1,151 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/tin3ga/shortly/geoip"
+	"github.com/tin3ga/shortly/internal/database"
+)
+
+const maxUTMLength = 200
+
+// UTM model info
+//
+//	@Description	UTM parameters appended to the destination on every redirect
+//	@Description	Source, Medium, Campaign, Term, Content
+type UTMModel struct {
+	Source   string `json:"source"`
+	Medium   string `json:"medium"`
+	Campaign string `json:"campaign"`
+	Term     string `json:"term"`
+	Content  string `json:"content"`
+}
+
+// utmParams holds the stored UTM values of a link as query parameters
+type utmParams struct {
+	Source, Medium, Campaign, Term, Content sql.NullString
+}
+
+func newUTMParams(input UTMModel) (utmParams, error) {
+	var params utmParams
+	fields := []struct {
+		name  string
+		value string
+		dst   *sql.NullString
+	}{
+		{"source", input.Source, &params.Source},
+		{"medium", input.Medium, &params.Medium},
+		{"campaign", input.Campaign, &params.Campaign},
+		{"term", input.Term, &params.Term},
+		{"content", input.Content, &params.Content},
+	}
+	for _, field := range fields {
+		value := strings.TrimSpace(field.value)
+		if len(value) > maxUTMLength {
+			return utmParams{}, fmt.Errorf("utm %v must be at most %d characters", field.name, maxUTMLength)
+		}
+		*field.dst = nullString(value)
+	}
+	return params, nil
+}
+
+// storedUTM returns the UTM parameters of a link that are set, keyed by their query parameter name
+func storedUTM(data database.Shortly) map[string]string {
+	utm := make(map[string]string)
+	for key, value := range map[string]sql.NullString{
+		"utm_source":   data.UtmSource,
+		"utm_medium":   data.UtmMedium,
+		"utm_campaign": data.UtmCampaign,
+		"utm_term":     data.UtmTerm,
+		"utm_content":  data.UtmContent,
+	} {
+		if value.Valid {
+			utm[key] = value.String
+		}
+	}
+	return utm
+}
+
+// visitorDestination returns where the visitor is sent. Targeting rules come first, then the schedule,
+// then a split test variant, then the link's url. The forwarded query and stored UTM parameters are added
+// to whichever is picked. Visits from a QR code are counted and their ?src=qr tag is never forwarded.
+func visitorDestination(c *fiber.Ctx, ctx context.Context, queries *database.Queries, geo *geoip.Reader, data database.Shortly) string {
+	destination, ok := targetedDestination(c, geo, data)
+	if !ok {
+		destination, ok = scheduledDestination(data, time.Now())
+	}
+	if !ok {
+		destination = pickVariant(c, ctx, queries, data)
+	}
+
+	visitorQuery := string(c.Request().URI().QueryString())
+	if isQRScan(c) {
+		countQRScan(ctx, queries, data)
+		visitorQuery = withoutQRSource(visitorQuery)
+	}
+	return buildDestination(destination, data, visitorQuery)
+}
+
+// buildDestination adds the forwarded visitor query and the stored UTM parameters to destination.
+// Precedence from lowest to highest: visitor query, the query of the destination, stored UTM parameters.
+// Visitor parameters are only forwarded when the link enables it and never replace a parameter of the destination.
+func buildDestination(destination string, data database.Shortly, visitorQuery string) string {
+	utm := storedUTM(data)
+	forward := data.ForwardQuery && visitorQuery != ""
+	if !forward && len(utm) == 0 {
+		return destination
+	}
+
+	u, err := url.Parse(destination)
+	if err != nil {
+		return destination
+	}
+	query := u.Query()
+
+	if forward {
+		visitor, _ := url.ParseQuery(visitorQuery)
+		for key, values := range visitor {
+			if _, exists := query[key]; !exists {
+				query[key] = values
+			}
+		}
+	}
+
+	for key, value := range utm {
+		query.Set(key, value)
+	}
+
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+// setQueryOptions updates the query string options of a link, nil arguments keep the current value
+func setQueryOptions(ctx context.Context, queries *database.Queries, data database.Shortly, forwardQuery *bool, utm *utmParams) (database.Shortly, error) {
+	params := database.SetLinkQueryOptionsParams{
+		ID:           data.ID,
+		ForwardQuery: data.ForwardQuery,
+		UtmSource:    data.UtmSource,
+		UtmMedium:    data.UtmMedium,
+		UtmCampaign:  data.UtmCampaign,
+		UtmTerm:      data.UtmTerm,
+		UtmContent:   data.UtmContent,
+	}
+	if forwardQuery != nil {
+		params.ForwardQuery = *forwardQuery
+	}
+	if utm != nil {
+		params.UtmSource = utm.Source
+		params.UtmMedium = utm.Medium
+		params.UtmCampaign = utm.Campaign
+		params.UtmTerm = utm.Term
+		params.UtmContent = utm.Content
+	}
+	return queries.SetLinkQueryOptions(ctx, params)
+}
@@ -0,0 +1,410 @@
+package handler
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"log"
+	"net"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/tin3ga/shortly/alias"
+	"github.com/tin3ga/shortly/internal/database"
+)
+
+// cached value for hosts that are not a registered domain
+const defaultDomainCacheValue = "default"
+
+var hostRegex = regexp.MustCompile(`^([a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?\.)+[a-z]{2,63}$`)
+
+// verificationPrefix is the label of the TXT record that proves ownership of a domain
+const verificationPrefix = "_shortly-verify."
+
+// Domain model info
+//
+//	@Description	Domain Model
+//	@Description	Host
+type DomainModel struct {
+	// Host name without scheme or port, e.g. go.example.com
+	Host string `json:"host"`
+}
+
+// normalizeHost lower cases a host and strips any port
+func normalizeHost(host string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return strings.TrimSuffix(strings.ToLower(host), ".")
+}
+
+// cacheKey returns the redis key of a link, links on the default domain are keyed by alias only
+func cacheKey(domainID uuid.NullUUID, alias string) string {
+	if domainID.Valid {
+		return domainID.UUID.String() + ":" + alias
+	}
+	return alias
+}
+
+func domainCacheKey(host string) string {
+	return "domain:" + host
+}
+
+// verificationRecord returns the name of the TXT record that must hold the verification token of host
+func verificationRecord(host string) string {
+	return verificationPrefix + host
+}
+
+// newVerificationToken returns a random token for the TXT record of a domain
+func newVerificationToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "shortly-verify=" + hex.EncodeToString(b), nil
+}
+
+// resolveDomain maps the request host to a verified domain, unknown and unverified hosts use the default domain
+func resolveDomain(ctx context.Context, queries *database.Queries, rdb *redis.Client, ttl time.Duration, host string) (uuid.NullUUID, error) {
+	host = normalizeHost(host)
+
+	if rdb != nil {
+		val, err := rdb.Get(ctx, domainCacheKey(host)).Result()
+		if err == nil {
+			if val == defaultDomainCacheValue {
+				return uuid.NullUUID{}, nil
+			}
+			if id, err := uuid.Parse(val); err == nil {
+				return uuid.NullUUID{UUID: id, Valid: true}, nil
+			}
+		}
+	}
+
+	var domainID uuid.NullUUID
+	domain, err := queries.GetVerifiedDomainByHost(ctx, host)
+	if err == nil {
+		domainID = uuid.NullUUID{UUID: domain.ID, Valid: true}
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return uuid.NullUUID{}, err
+	}
+
+	if rdb != nil {
+		val := defaultDomainCacheValue
+		if domainID.Valid {
+			val = domainID.UUID.String()
+		}
+		if err := rdb.Set(ctx, domainCacheKey(host), val, ttl).Err(); err != nil {
+			log.Print(err)
+		}
+	}
+
+	return domainID, nil
+}
+
+// aliasKeyPrefix marks cache keys of links looked up by their alias key, aliases cannot contain a colon
+const aliasKeyPrefix = "key:"
+
+// lookupLink fetches a link by alias on the given domain
+func lookupLink(ctx context.Context, queries *database.Queries, domainID uuid.NullUUID, link string) (database.Shortly, error) {
+	link = alias.Normalize(link)
+	if domainID.Valid {
+		return queries.GetDomainLink(ctx, database.GetDomainLinkParams{ShortLink: link, DomainID: domainID})
+	}
+	return queries.GetLongLink(ctx, link)
+}
+
+// findLink fetches the link a visitor asked for, when the policy ignores case an alias without an exact
+// match is matched by its key, the oldest link wins
+func findLink(ctx context.Context, queries *database.Queries, policy *alias.Policy, domainID uuid.NullUUID, link string) (database.Shortly, error) {
+	data, err := lookupLink(ctx, queries, domainID, link)
+	if errors.Is(err, sql.ErrNoRows) && policy.CaseInsensitive {
+		return queries.GetLinkByAliasKey(ctx, database.GetLinkByAliasKeyParams{AliasKey: alias.Key(link), DomainID: domainID})
+	}
+	return data, err
+}
+
+// linkCacheKey returns the redis key a visitor's alias is cached under, every spelling of an alias shares
+// one key when the policy ignores case
+func linkCacheKey(policy *alias.Policy, domainID uuid.NullUUID, link string) string {
+	if policy.CaseInsensitive {
+		return cacheKey(domainID, aliasKeyPrefix+alias.Key(link))
+	}
+	return cacheKey(domainID, alias.Normalize(link))
+}
+
+// aliasParam returns a path parameter holding an alias, percent-decoded so Unicode aliases typed in a browser match
+func aliasParam(c *fiber.Ctx, name string) string {
+	value := c.Params(name)
+	if decoded, err := url.PathUnescape(value); err == nil {
+		return decoded
+	}
+	return value
+}
+
+// getOwnedDomain fetches a domain by host and checks that it belongs to the authenticated user
+func getOwnedDomain(c *fiber.Ctx, queries *database.Queries, ctx context.Context, host string) (database.Domain, error) {
+	userID, err := getUserID(c)
+	if err != nil {
+		log.Printf("Error parsing UserID: %v", err)
+		return database.Domain{}, fiber.NewError(fiber.StatusBadRequest, "Invalid UserID format")
+	}
+
+	domain, err := queries.GetUserDomainByHost(ctx, database.GetUserDomainByHostParams{UserID: userID, Host: normalizeHost(host)})
+	if err != nil {
+		return database.Domain{}, fiber.NewError(fiber.StatusNotFound, "domain not found")
+	}
+
+	return domain, nil
+}
+
+// unverifiedDomainMessage rejects links on a domain that does not resolve yet
+const unverifiedDomainMessage = "Domain is not verified yet, verify it before adding links"
+
+// ownedDomainID resolves an optional domain host sent by the user, an empty host means the default domain
+func ownedDomainID(c *fiber.Ctx, queries *database.Queries, ctx context.Context, host string) (uuid.NullUUID, error) {
+	if host == "" {
+		return uuid.NullUUID{}, nil
+	}
+	domain, err := getOwnedDomain(c, queries, ctx, host)
+	if err != nil {
+		return uuid.NullUUID{}, err
+	}
+	return uuid.NullUUID{UUID: domain.ID, Valid: true}, nil
+}
+
+// verifiedDomainID resolves the domain a new link is added to, resolveDomain ignores unverified domains so
+// their links would never resolve
+func verifiedDomainID(c *fiber.Ctx, queries *database.Queries, ctx context.Context, host string) (uuid.NullUUID, error) {
+	if host == "" {
+		return uuid.NullUUID{}, nil
+	}
+	domain, err := getOwnedDomain(c, queries, ctx, host)
+	if err != nil {
+		return uuid.NullUUID{}, err
+	}
+	if !domain.VerifiedAt.Valid {
+		return uuid.NullUUID{}, fiber.NewError(fiber.StatusBadRequest, unverifiedDomainMessage)
+	}
+	return uuid.NullUUID{UUID: domain.ID, Valid: true}, nil
+}
+
+// createDomain Register a custom domain
+//
+//	@Summary		Register a custom domain
+//	@Description	The domain resolves links from the request Host header once it is verified
+//	@Description	Publish the returned token as a TXT record, then call the verify endpoint
+//	@Param			domain	body	DomainModel	true	"Domain to register"
+//	@Tags			protected
+//	@Security		BearerAuth
+//	@Produce		json
+//	@Success		201
+//	@Failure		400
+//	@Failure		409
+//	@Failure		500
+//	@Router			/api/v1/domains/ [post]
+func CreateDomain(c *fiber.Ctx, queries *database.Queries, ctx context.Context) error {
+	input := new(DomainModel)
+
+	if err := c.BodyParser(input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Cannot parse JSON"})
+	}
+
+	host := normalizeHost(input.Host)
+	if !hostRegex.MatchString(host) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid host name", "host": input.Host})
+	}
+
+	userID, err := getUserID(c)
+	if err != nil {
+		log.Printf("Error parsing UserID: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"success": false, "message": "Invalid UserID format"})
+	}
+
+	// an unverified claim does not block the host, only verifying proves ownership
+	if _, err := queries.GetVerifiedDomainByHost(ctx, host); err == nil {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "Domain is already registered", "host": host})
+	}
+
+	token, err := newVerificationToken()
+	if err != nil {
+		log.Print(err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Cannot register domain"})
+	}
+
+	domain, err := queries.CreateDomain(ctx, database.CreateDomainParams{
+		ID:                uuid.New(),
+		UserID:            userID,
+		Host:              host,
+		VerificationToken: token,
+	})
+	if err != nil {
+		log.Print(err)
+		if strings.Contains(err.Error(), "unique_user_host") {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "Domain is already registered", "host": host})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Cannot register domain"})
+	}
+
+	log.Println("Registered domain: ", host)
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"Success": "Domain registered, publish the TXT record and verify it",
+		"Data":    domain,
+		"Record":  fiber.Map{"type": "TXT", "name": verificationRecord(host), "value": token},
+	})
+}
+
+// verifyDomain Verify the ownership of a custom domain
+//
+//	@Summary		Verify the ownership of a custom domain
+//	@Description	Looks up the TXT record returned on registration, the domain resolves links once it matches
+//	@Param			host	path	string	true	"Domain host"
+//	@Tags			protected
+//	@Security		BearerAuth
+//	@Produce		json
+//	@Success		200
+//	@Failure		400
+//	@Failure		404
+//	@Failure		409
+//	@Failure		422
+//	@Failure		500
+//	@Router			/api/v1/domains/{host}/verify [post]
+func VerifyDomain(c *fiber.Ctx, queries *database.Queries, ctx context.Context, rdb *redis.Client) error {
+	domain, err := getOwnedDomain(c, queries, ctx, c.Params("host"))
+	if err != nil {
+		return errorResponse(c, err)
+	}
+	if domain.VerifiedAt.Valid {
+		return c.JSON(fiber.Map{"Success": "Domain is verified", "Data": domain})
+	}
+
+	records, err := net.DefaultResolver.LookupTXT(ctx, verificationRecord(domain.Host))
+	if err != nil {
+		log.Print(err)
+	}
+	if !slices.Contains(records, domain.VerificationToken) {
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+			"error":  "Verification record not found",
+			"Record": fiber.Map{"type": "TXT", "name": verificationRecord(domain.Host), "value": domain.VerificationToken},
+		})
+	}
+
+	verified, err := queries.VerifyDomain(ctx, domain.ID)
+	if err != nil {
+		log.Print(err)
+		if strings.Contains(err.Error(), "unique_verified_host") {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "Domain is verified by another user", "host": domain.Host})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Cannot verify domain"})
+	}
+
+	// the host may be cached as the default domain
+	if rdb != nil {
+		if err := rdb.Del(ctx, domainCacheKey(domain.Host)).Err(); err != nil {
+			log.Print(err)
+		}
+	}
+
+	log.Println("Verified domain: ", verified.Host)
+	return c.JSON(fiber.Map{"Success": "Domain verified", "Data": verified})
+}
+
+// deleteDomain removes a domain together with its trashed links, they would otherwise keep it referenced
+// until the trash purger deletes them
+func deleteDomain(ctx context.Context, db *sql.DB, queries *database.Queries, domainID uuid.UUID) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	qtx := queries.WithTx(tx)
+
+	if _, err := qtx.PurgeTrashedDomainLinks(ctx, uuid.NullUUID{UUID: domainID, Valid: true}); err != nil {
+		return err
+	}
+	if err := qtx.DeleteDomain(ctx, domainID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// isForeignKeyViolation reports whether err was caused by a row that is still referenced
+func isForeignKeyViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23503"
+}
+
+// getDomains Fetch the custom domains of a user
+//
+//	@Summary		Fetch the custom domains of a user
+//	@Description	Returns all domains registered by the user
+//	@Tags			protected
+//	@Security		BearerAuth
+//	@Produce		json
+//	@Success		200
+//	@Failure		400
+//	@Failure		404
+//	@Router			/api/v1/domains/ [get]
+func GetDomains(c *fiber.Ctx, queries *database.Queries, ctx context.Context) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		log.Printf("Error parsing UserID: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"success": false, "message": "Invalid UserID format"})
+	}
+
+	domains, err := queries.GetUserDomains(ctx, userID)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Cannot fetch domains"})
+	}
+
+	return c.JSON(domains)
+}
+
+// deleteDomain Remove a custom domain
+//
+//	@Summary		Remove a custom domain
+//	@Description	Domains that still have links cannot be removed, links of the domain in the trash are deleted for good
+//	@Param			host	path	string	true	"Domain host"
+//	@Tags			protected
+//	@Security		BearerAuth
+//	@Produce		json
+//	@Success		200
+//	@Failure		400
+//	@Failure		404
+//	@Failure		409
+//	@Failure		500
+//	@Router			/api/v1/domains/{host} [delete]
+func DeleteDomain(c *fiber.Ctx, db *sql.DB, queries *database.Queries, ctx context.Context, rdb *redis.Client) error {
+	domain, err := getOwnedDomain(c, queries, ctx, c.Params("host"))
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	if err := deleteDomain(ctx, db, queries, domain.ID); err != nil {
+		log.Print(err)
+		if isForeignKeyViolation(err) {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "Domain still has links, delete them first", "host": domain.Host})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Cannot remove domain"})
+	}
+
+	if rdb != nil {
+		if err := rdb.Del(ctx, domainCacheKey(domain.Host)).Err(); err != nil {
+			log.Print(err)
+		}
+	}
+
+	log.Println("Removed domain: ", domain.Host)
+	return c.JSON(fiber.Map{"Success": "Domain removed"})
+}
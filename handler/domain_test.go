@@ -0,0 +1,41 @@
+package handler
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/tin3ga/shortly/internal/database"
+)
+
+func TestDeleteDomainWithTrashedLinks(t *testing.T) {
+	db, queries := openTestDB(t)
+	ctx := context.Background()
+	userID := uuid.MustParse(seedUserID)
+
+	domain, err := queries.CreateDomain(ctx, database.CreateDomainParams{ID: uuid.New(), UserID: userID, Host: "go.example.com", VerificationToken: "token"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	linkID := uuid.New()
+	_, err = db.Exec(`INSERT INTO shortly(id, user_id, short_link, long_link, alias_key, domain_id) VALUES($1, $2, 'docs', 'https://example.com', 'docs', $3)`,
+		linkID, userID, domain.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := deleteDomain(ctx, db, queries, domain.ID); !isForeignKeyViolation(err) {
+		t.Fatalf("deleting a domain with a live link: got %v, want a foreign key violation", err)
+	}
+
+	if _, err := queries.TrashLink(ctx, linkID); err != nil {
+		t.Fatal(err)
+	}
+	if err := deleteDomain(ctx, db, queries, domain.ID); err != nil {
+		t.Fatalf("deleting a domain with a trashed link: %v", err)
+	}
+	if _, err := queries.GetDomain(ctx, domain.ID); err == nil {
+		t.Error("domain still exists")
+	}
+}
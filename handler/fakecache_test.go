@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// fakeCache answers the GET, SET and DEL commands of a redis client in memory, the client never dials
+type fakeCache struct {
+	mu     sync.Mutex
+	values map[string]string
+	ttls   map[string]time.Duration
+}
+
+// newFakeCache returns a redis client backed by a fakeCache
+func newFakeCache(t *testing.T) (*fakeCache, *redis.Client) {
+	t.Helper()
+
+	cache := &fakeCache{values: make(map[string]string), ttls: make(map[string]time.Duration)}
+	rdb := redis.NewClient(&redis.Options{Addr: "fake-cache:6379"})
+	rdb.AddHook(cache)
+	t.Cleanup(func() { rdb.Close() })
+	return cache, rdb
+}
+
+func (f *fakeCache) get(key string) (string, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	value, ok := f.values[key]
+	return value, ok
+}
+
+func (f *fakeCache) ttl(key string) time.Duration {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.ttls[key]
+}
+
+func (f *fakeCache) DialHook(next redis.DialHook) redis.DialHook { return next }
+
+func (f *fakeCache) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		return fmt.Errorf("fake cache: pipelines are not supported")
+	}
+}
+
+func (f *fakeCache) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+
+		args := make([]string, len(cmd.Args()))
+		for i, arg := range cmd.Args() {
+			switch v := arg.(type) {
+			case []byte:
+				args[i] = string(v)
+			default:
+				args[i] = fmt.Sprint(v)
+			}
+		}
+
+		switch c := cmd.(type) {
+		case *redis.StringCmd:
+			if value, ok := f.values[args[1]]; ok && strings.EqualFold(args[0], "get") {
+				c.SetVal(value)
+				return nil
+			}
+			c.SetErr(redis.Nil)
+			return redis.Nil
+
+		case *redis.StatusCmd:
+			if !strings.EqualFold(args[0], "set") {
+				break
+			}
+			f.values[args[1]] = args[2]
+			f.ttls[args[1]] = 0
+			if len(args) == 5 {
+				n, _ := strconv.Atoi(args[4])
+				unit := time.Second
+				if strings.EqualFold(args[3], "px") {
+					unit = time.Millisecond
+				}
+				f.ttls[args[1]] = time.Duration(n) * unit
+			}
+			c.SetVal("OK")
+			return nil
+
+		case *redis.IntCmd:
+			if !strings.EqualFold(args[0], "del") {
+				break
+			}
+			var count int64
+			for _, key := range args[1:] {
+				if _, ok := f.values[key]; ok {
+					delete(f.values, key)
+					count++
+				}
+			}
+			c.SetVal(count)
+			return nil
+		}
+
+		err := fmt.Errorf("fake cache: unsupported command %v", args[0])
+		cmd.SetErr(err)
+		return err
+	}
+}
@@ -0,0 +1,327 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+
+	"github.com/tin3ga/shortly/internal/database"
+)
+
+// fakeStore keeps links and revisions in memory and answers the generated queries the redirect, edit and
+// rollback handlers run, it mirrors the WHERE clauses of those queries in sql/queries
+type fakeStore struct {
+	mu        sync.Mutex
+	links     map[uuid.UUID]database.Shortly
+	revisions []database.LinkRevision
+}
+
+// newFakeStore returns queries and a database backed by a fakeStore
+func newFakeStore(t *testing.T) (*fakeStore, *sql.DB, *database.Queries) {
+	t.Helper()
+
+	store := &fakeStore{links: make(map[uuid.UUID]database.Shortly)}
+	db := sql.OpenDB(store)
+	t.Cleanup(func() { db.Close() })
+	return store, db, database.New(db)
+}
+
+// addLink stores a link with the column defaults of the schema
+func (s *fakeStore) addLink(link database.Shortly) database.Shortly {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if link.ID == uuid.Nil {
+		link.ID = uuid.New()
+	}
+	if link.AliasKey == "" {
+		link.AliasKey = strings.ToLower(link.ShortLink)
+	}
+	for _, rules := range []*[]byte{(*[]byte)(&link.TargetingRules), (*[]byte)(&link.GeoRules), (*[]byte)(&link.Variants), (*[]byte)(&link.Schedule)} {
+		if len(*rules) == 0 {
+			*rules = []byte("[]")
+		}
+	}
+	if link.ScheduleTimezone == "" {
+		link.ScheduleTimezone = "UTC"
+	}
+	link.CreatedAt = time.Now()
+	link.UpdatedAt = link.CreatedAt
+	s.links[link.ID] = link
+	return link
+}
+
+// link returns the stored copy of a link
+func (s *fakeStore) link(id uuid.UUID) database.Shortly {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.links[id]
+}
+
+var queryName = regexp.MustCompile(`^-- name: (\w+)`)
+
+// run answers a query with rows of structs or plain values
+func (s *fakeStore) run(query string, args []driver.NamedValue) ([]any, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	name := queryName.FindStringSubmatch(query)
+	if name == nil {
+		return nil, fmt.Errorf("fake store: query without a name: %v", query)
+	}
+	arg := func(i int) driver.Value { return args[i].Value }
+
+	switch name[1] {
+	case "GetVerifiedDomainByHost", "GetArchivedLink", "GetTagsForLinks":
+		return nil, nil
+
+	case "GetLongLink":
+		return s.find(func(l database.Shortly) bool {
+			return l.ShortLink == arg(0) && !l.DomainID.Valid && !l.DeletedAt.Valid
+		}), nil
+
+	case "GetLinkByAliasKey":
+		return s.find(func(l database.Shortly) bool {
+			return l.AliasKey == arg(0) && nullUUIDEqual(l.DomainID, arg(1)) && !l.DeletedAt.Valid
+		}), nil
+
+	case "GetLinkForUpdate":
+		return s.find(func(l database.Shortly) bool { return l.ID.String() == arg(0) }), nil
+
+	case "ClaimClick":
+		rows := s.find(func(l database.Shortly) bool {
+			return l.ID.String() == arg(0) && (!l.MaxClicks.Valid || l.ClickCount < l.MaxClicks.Int32) &&
+				(!l.ExpiresAt.Valid || l.ExpiresAt.Time.After(time.Now()))
+		})
+		return s.update(rows, func(l *database.Shortly) { l.ClickCount++ }), nil
+
+	case "SetLinkEnabled":
+		return s.update(s.byID(arg(0)), func(l *database.Shortly) { l.Enabled = arg(1).(bool) }), nil
+
+	case "SetLinkActivation":
+		return s.update(s.byID(arg(0)), func(l *database.Shortly) { l.ActivateAt = nullTime(arg(1)) }), nil
+
+	case "UpdateLink":
+		return s.update(s.byID(arg(0)), func(l *database.Shortly) {
+			l.ShortLink = arg(1).(string)
+			l.LongLink = arg(2).(string)
+			l.RedirectType = nullInt32(arg(3))
+			l.ScanVerdict = nullString(stringValue(arg(4)))
+			l.NormalizedLink = nullString(stringValue(arg(5)))
+			l.AliasKey = arg(6).(string)
+			l.AliasKeyUnique = arg(7).(bool)
+		}), nil
+
+	case "CountLinkRevisions":
+		var count int64
+		for _, r := range s.revisions {
+			if r.LinkID.String() == arg(0) {
+				count++
+			}
+		}
+		return []any{[]driver.Value{count}}, nil
+
+	case "CreateLinkRevision":
+		revision := database.LinkRevision{
+			ID:           uuid.MustParse(arg(0).(string)),
+			LinkID:       uuid.MustParse(arg(1).(string)),
+			Revision:     1,
+			ShortLink:    arg(2).(string),
+			LongLink:     arg(3).(string),
+			RedirectType: nullInt32(arg(4)),
+			CreatedAt:    time.Now(),
+		}
+		for _, r := range s.revisions {
+			if r.LinkID == revision.LinkID && r.Revision >= revision.Revision {
+				revision.Revision = r.Revision + 1
+			}
+		}
+		s.revisions = append(s.revisions, revision)
+		return []any{revision}, nil
+
+	case "GetLinkRevision":
+		for _, r := range s.revisions {
+			if r.LinkID.String() == arg(0) && int64(r.Revision) == arg(1) {
+				return []any{r}, nil
+			}
+		}
+		return nil, nil
+	}
+
+	return nil, fmt.Errorf("fake store: unsupported query %v", name[1])
+}
+
+func (s *fakeStore) find(match func(database.Shortly) bool) []any {
+	var rows []any
+	for _, link := range s.links {
+		if match(link) {
+			rows = append(rows, link)
+		}
+	}
+	return rows
+}
+
+func (s *fakeStore) byID(id driver.Value) []any {
+	return s.find(func(l database.Shortly) bool { return l.ID.String() == id })
+}
+
+// update changes the matched links and returns them as updated
+func (s *fakeStore) update(rows []any, change func(*database.Shortly)) []any {
+	for i, row := range rows {
+		link := row.(database.Shortly)
+		change(&link)
+		link.UpdatedAt = time.Now()
+		s.links[link.ID] = link
+		rows[i] = link
+	}
+	return rows
+}
+
+func nullUUIDEqual(id uuid.NullUUID, value driver.Value) bool {
+	if value == nil {
+		return !id.Valid
+	}
+	return id.Valid && id.UUID.String() == value
+}
+
+func nullTime(value driver.Value) sql.NullTime {
+	t, ok := value.(time.Time)
+	return sql.NullTime{Time: t, Valid: ok}
+}
+
+func nullInt32(value driver.Value) sql.NullInt32 {
+	n, ok := value.(int64)
+	return sql.NullInt32{Int32: int32(n), Valid: ok}
+}
+
+func stringValue(value driver.Value) string {
+	s, _ := value.(string)
+	return s
+}
+
+// columns returns the result columns of a generated query
+func columns(query string) []string {
+	list := query
+	if i := strings.LastIndex(query, "RETURNING "); i >= 0 {
+		list = query[i+len("RETURNING "):]
+	} else if i := strings.Index(query, "SELECT "); i >= 0 {
+		list = query[i+len("SELECT "):]
+		if end := strings.Index(list, " FROM "); end >= 0 {
+			list = list[:end]
+		}
+	}
+	list = strings.TrimSuffix(strings.TrimSpace(list), ";")
+
+	var cols []string
+	for _, col := range strings.Split(list, ",") {
+		cols = append(cols, strings.TrimSpace(col))
+	}
+	return cols
+}
+
+// rowValues returns the values of the columns of a struct, columns are matched to fields by name without
+// underscores so json tags like "-" do not matter
+func rowValues(row any, cols []string) ([]driver.Value, error) {
+	if values, ok := row.([]driver.Value); ok {
+		return values, nil
+	}
+
+	v := reflect.ValueOf(row)
+	values := make([]driver.Value, len(cols))
+	for i, col := range cols {
+		field := v.FieldByNameFunc(func(name string) bool {
+			return strings.EqualFold(name, strings.ReplaceAll(col, "_", ""))
+		})
+		if !field.IsValid() {
+			return nil, fmt.Errorf("fake store: %T has no column %v", row, col)
+		}
+
+		value := field.Interface()
+		if list, ok := value.([]string); ok {
+			value = pq.Array(list)
+		}
+		converted, err := driver.DefaultParameterConverter.ConvertValue(value)
+		if err != nil {
+			return nil, fmt.Errorf("fake store: column %v: %w", col, err)
+		}
+		values[i] = converted
+	}
+	return values, nil
+}
+
+// Connect and Driver make the store a driver.Connector for sql.OpenDB
+func (s *fakeStore) Connect(context.Context) (driver.Conn, error) { return &fakeConn{store: s}, nil }
+
+func (s *fakeStore) Driver() driver.Driver { return nil }
+
+type fakeConn struct {
+	store *fakeStore
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, fmt.Errorf("fake store: prepared statements are not supported")
+}
+
+func (c *fakeConn) Close() error { return nil }
+
+// Begin starts a transaction, the fake applies every statement at once and cannot roll back
+func (c *fakeConn) Begin() (driver.Tx, error) { return c, nil }
+
+func (c *fakeConn) Commit() error { return nil }
+
+func (c *fakeConn) Rollback() error { return nil }
+
+func (c *fakeConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	rows, err := c.store.run(query, args)
+	if err != nil {
+		return nil, err
+	}
+
+	cols := columns(query)
+	result := &fakeRows{columns: cols}
+	for _, row := range rows {
+		values, err := rowValues(row, cols)
+		if err != nil {
+			return nil, err
+		}
+		result.rows = append(result.rows, values)
+	}
+	return result, nil
+}
+
+func (c *fakeConn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	rows, err := c.store.run(query, args)
+	if err != nil {
+		return nil, err
+	}
+	return driver.RowsAffected(len(rows)), nil
+}
+
+type fakeRows struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string { return r.columns }
+
+func (r *fakeRows) Close() error { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
@@ -0,0 +1,45 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"log"
+
+	"github.com/google/uuid"
+
+	"github.com/tin3ga/shortly/internal/database"
+	"github.com/tin3ga/shortly/metadata"
+)
+
+// fetchLinkMetadata loads the title, description, favicon and Open Graph tags of the destination in the
+// background and stores them on the link, failures are only logged
+func fetchLinkMetadata(ctx context.Context, queries *database.Queries, fetcher metadata.Fetcher, linkID uuid.UUID, link string) {
+	if fetcher == nil {
+		return
+	}
+
+	go func() {
+		meta, err := fetcher.Fetch(ctx, link)
+		if err != nil {
+			log.Printf("Cannot fetch metadata of %v: %v", link, err)
+			return
+		}
+
+		err = queries.SetLinkMetadata(ctx, database.SetLinkMetadataParams{
+			ID:            linkID,
+			Title:         nullString(meta.Title),
+			Description:   nullString(meta.Description),
+			FaviconUrl:    nullString(meta.Favicon),
+			OgTitle:       nullString(meta.OGTitle),
+			OgDescription: nullString(meta.OGDescription),
+			OgImage:       nullString(meta.OGImage),
+		})
+		if err != nil {
+			log.Print(err)
+		}
+	}()
+}
+
+func nullString(value string) sql.NullString {
+	return sql.NullString{String: value, Valid: value != ""}
+}
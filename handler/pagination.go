@@ -0,0 +1,214 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"github.com/tin3ga/shortly/internal/database"
+)
+
+const (
+	defaultPageLimit = 50
+	maxPageLimit     = 200
+)
+
+// Sort orders of the link listings
+const (
+	SortCreatedAt  = "created_at"
+	SortClickCount = "click_count"
+	SortAlias      = "alias"
+	sortRank       = "rank" // search results only
+)
+
+// linkCursor is the position after the last link of a page, it is sent to clients base64 encoded
+type linkCursor struct {
+	Sort      string    `json:"s"`
+	ID        uuid.UUID `json:"i"`
+	CreatedAt time.Time `json:"t,omitempty"`
+	Clicks    int32     `json:"c,omitempty"`
+	Alias     string    `json:"a,omitempty"`
+	Rank      float32   `json:"r,omitempty"`
+}
+
+func encodeCursor(cursor linkCursor) string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(value string) (linkCursor, error) {
+	var cursor linkCursor
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return cursor, err
+	}
+	err = json.Unmarshal(data, &cursor)
+	return cursor, err
+}
+
+// linkListing holds the sort order, filters and page of a link listing request
+type linkListing struct {
+	Sort            string
+	Limit           int32
+	Cursor          *linkCursor
+	UserID          uuid.NullUUID
+	Folder          sql.NullString
+	Tag             sql.NullString
+	CreatedFrom     sql.NullTime
+	CreatedTo       sql.NullTime
+	DestinationHost sql.NullString
+}
+
+// parseListTime accepts an RFC3339 time or a date, a date used as upper bound includes the whole day
+func parseListTime(value string, upper bool) (sql.NullTime, error) {
+	if value == "" {
+		return sql.NullTime{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return sql.NullTime{Time: t.UTC(), Valid: true}, nil
+	}
+	t, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		return sql.NullTime{}, err
+	}
+	if upper {
+		t = t.AddDate(0, 0, 1)
+	}
+	return sql.NullTime{Time: t, Valid: true}, nil
+}
+
+// parsePage reads the limit and cursor query parameters, the cursor must come from a page with the same sort
+func parsePage(c *fiber.Ctx, sort string) (int32, *linkCursor, error) {
+	limit := int32(c.QueryInt("limit", defaultPageLimit))
+	if limit < 1 || limit > maxPageLimit {
+		return 0, nil, fiber.NewError(fiber.StatusBadRequest, "limit must be between 1 and 200")
+	}
+
+	value := c.Query("cursor")
+	if value == "" {
+		return limit, nil, nil
+	}
+
+	cursor, err := decodeCursor(value)
+	if err != nil || cursor.Sort != sort {
+		return 0, nil, fiber.NewError(fiber.StatusBadRequest, "Invalid cursor")
+	}
+
+	return limit, &cursor, nil
+}
+
+// parseLinkListing reads the pagination, sort and filter query parameters shared by the link listings
+func parseLinkListing(c *fiber.Ctx) (linkListing, error) {
+	listing := linkListing{Sort: c.Query("sort", SortCreatedAt)}
+
+	switch listing.Sort {
+	case SortCreatedAt, SortClickCount, SortAlias:
+	default:
+		return listing, fiber.NewError(fiber.StatusBadRequest, "sort must be one of created_at, click_count or alias")
+	}
+
+	var err error
+	if listing.Limit, listing.Cursor, err = parsePage(c, listing.Sort); err != nil {
+		return listing, err
+	}
+
+	if listing.CreatedFrom, err = parseListTime(c.Query("created_from"), false); err != nil {
+		return listing, fiber.NewError(fiber.StatusBadRequest, "created_from must be an RFC3339 time or a date (2006-01-02)")
+	}
+	if listing.CreatedTo, err = parseListTime(c.Query("created_to"), true); err != nil {
+		return listing, fiber.NewError(fiber.StatusBadRequest, "created_to must be an RFC3339 time or a date (2006-01-02)")
+	}
+
+	if host := c.Query("destination_host"); host != "" {
+		listing.DestinationHost = sql.NullString{String: strings.ToLower(host), Valid: true}
+	}
+
+	return listing, nil
+}
+
+// listLinks fetches one page of links and returns the cursor of the next page, empty on the last page
+func listLinks(ctx context.Context, queries *database.Queries, listing linkListing) ([]database.Shortly, string, error) {
+	// fetch one extra row to find out whether there is a next page
+	limit := listing.Limit + 1
+	cursor := listing.Cursor
+	if cursor == nil {
+		cursor = &linkCursor{}
+	}
+	cursorID := uuid.NullUUID{UUID: cursor.ID, Valid: listing.Cursor != nil}
+
+	var links []database.Shortly
+	var err error
+
+	switch listing.Sort {
+	case SortClickCount:
+		links, err = queries.ListLinksByClicks(ctx, database.ListLinksByClicksParams{
+			UserID:          listing.UserID,
+			Folder:          listing.Folder,
+			Tag:             listing.Tag,
+			CreatedFrom:     listing.CreatedFrom,
+			CreatedTo:       listing.CreatedTo,
+			DestinationHost: listing.DestinationHost,
+			CursorClicks:    sql.NullInt32{Int32: cursor.Clicks, Valid: listing.Cursor != nil},
+			CursorID:        cursorID,
+			PageLimit:       limit,
+		})
+	case SortAlias:
+		links, err = queries.ListLinksByAlias(ctx, database.ListLinksByAliasParams{
+			UserID:          listing.UserID,
+			Folder:          listing.Folder,
+			Tag:             listing.Tag,
+			CreatedFrom:     listing.CreatedFrom,
+			CreatedTo:       listing.CreatedTo,
+			DestinationHost: listing.DestinationHost,
+			CursorAlias:     sql.NullString{String: cursor.Alias, Valid: listing.Cursor != nil},
+			CursorID:        cursorID,
+			PageLimit:       limit,
+		})
+	default:
+		links, err = queries.ListLinksByCreated(ctx, database.ListLinksByCreatedParams{
+			UserID:          listing.UserID,
+			Folder:          listing.Folder,
+			Tag:             listing.Tag,
+			CreatedFrom:     listing.CreatedFrom,
+			CreatedTo:       listing.CreatedTo,
+			DestinationHost: listing.DestinationHost,
+			CursorCreatedAt: sql.NullTime{Time: cursor.CreatedAt, Valid: listing.Cursor != nil},
+			CursorID:        cursorID,
+			PageLimit:       limit,
+		})
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	if int32(len(links)) <= listing.Limit {
+		return links, "", nil
+	}
+
+	links = links[:listing.Limit]
+	last := links[len(links)-1]
+	next := encodeCursor(linkCursor{
+		Sort:      listing.Sort,
+		ID:        last.ID,
+		CreatedAt: last.CreatedAt,
+		Clicks:    last.ClickCount,
+		Alias:     last.ShortLink,
+	})
+
+	return links, next, nil
+}
+
+// pageResponse wraps a page of results with the cursor of the next page, null on the last page
+func pageResponse(data interface{}, next string) fiber.Map {
+	var nextCursor *string
+	if next != "" {
+		nextCursor = &next
+	}
+	return fiber.Map{"data": data, "next_cursor": nextCursor}
+}
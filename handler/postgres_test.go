@@ -0,0 +1,78 @@
+package handler
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	_ "github.com/lib/pq"
+
+	"github.com/tin3ga/shortly/internal/database"
+)
+
+// seedUserID is the first user inserted by the test data migration
+const seedUserID = "00008b11-6984-4b73-96e2-5722c445ba07"
+
+// openTestDB migrates a fresh schema of the database at TEST_DATABASE_URL, tests that need the queries to
+// run against postgres are skipped when it is not set
+func openTestDB(t *testing.T) (*sql.DB, *database.Queries) {
+	t.Helper()
+
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// a single connection keeps the search path of the test schema
+	db.SetMaxOpenConns(1)
+
+	schema := fmt.Sprintf("test_%d", time.Now().UnixNano())
+	if _, err := db.Exec("CREATE SCHEMA " + schema + "; SET search_path TO " + schema); err != nil {
+		db.Close()
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if _, err := db.Exec("DROP SCHEMA " + schema + " CASCADE"); err != nil {
+			t.Error(err)
+		}
+		db.Close()
+	})
+
+	files, err := filepath.Glob("../sql/schema/*.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range files {
+		migration, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := db.Exec(migrationUp(string(migration))); err != nil {
+			t.Fatalf("migrating %v: %v", filepath.Base(file), err)
+		}
+	}
+
+	return db, database.New(db)
+}
+
+// migrationUp returns the statements of the up section of a goose migration
+func migrationUp(migration string) string {
+	_, up, _ := strings.Cut(migration, "-- +goose Up")
+	up, _, _ = strings.Cut(up, "-- +goose Down")
+
+	var lines []string
+	for _, line := range strings.Split(up, "\n") {
+		if !strings.HasPrefix(strings.TrimSpace(line), "-- +goose") {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
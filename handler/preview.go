@@ -0,0 +1,133 @@
+package handler
+
+import (
+	"context"
+	"html/template"
+	"log"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/tin3ga/shortly/alias"
+	"github.com/tin3ga/shortly/geoip"
+	"github.com/tin3ga/shortly/internal/database"
+)
+
+var previewPage = template.Must(template.New("preview").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="robots" content="noindex">
+<title>Preview of /{{.Alias}}</title>
+<style>
+body { font-family: sans-serif; display: flex; justify-content: center; margin-top: 15vh; }
+main { width: 32rem; max-width: 90vw; }
+dt { font-weight: bold; margin-top: 0.75rem; }
+dd { margin: 0.25rem 0 0; overflow-wrap: anywhere; }
+.malicious { color: #b00020; font-weight: bold; }
+</style>
+</head>
+<body>
+<main>
+<h1>/{{.Alias}}</h1>
+{{if .Protected}}
+<p>This link is password protected, its destination is only shown after unlocking it.</p>
+{{else}}
+<dl>
+{{if .Title}}<dt>Title</dt><dd>{{.Title}}</dd>{{end}}
+<dt>Destination</dt><dd>{{.Destination}}</dd>
+<dt>Scan verdict</dt><dd{{if eq .Scan_verdict "malicious"}} class="malicious"{{end}}>{{if .Scan_verdict}}{{.Scan_verdict}}{{else}}unknown{{end}}</dd>
+</dl>
+{{end}}
+<dl>
+<dt>Created</dt><dd>{{.Created_at.Format "2 January 2006"}}</dd>
+<dt>Clicks</dt><dd>{{.Click_count}}</dd>
+</dl>
+<p><a href="/{{.Alias}}" rel="noreferrer">Continue to the link</a></p>
+</main>
+</body>
+</html>
+`))
+
+// LinkPreview is what the preview page shows about a link, destinations of protected links are hidden
+type LinkPreview struct {
+	Alias        string    `json:"alias"`
+	Destination  string    `json:"destination,omitempty"`
+	Title        string    `json:"title,omitempty"`
+	Scan_verdict string    `json:"scan_verdict,omitempty"`
+	Protected    bool      `json:"protected"`
+	Created_at   time.Time `json:"created_at"`
+	Click_count  int32     `json:"click_count"`
+}
+
+func newLinkPreview(data database.Shortly) LinkPreview {
+	preview := LinkPreview{
+		Alias:       data.ShortLink,
+		Protected:   data.PasswordHash.Valid,
+		Created_at:  data.CreatedAt,
+		Click_count: data.ClickCount,
+	}
+	if !preview.Protected {
+		preview.Destination = data.LongLink
+		preview.Title = data.Title.String
+		preview.Scan_verdict = data.ScanVerdict.String
+	}
+	return preview
+}
+
+// previewLink Show where a Short URL leads without following it
+//
+//	@Summary		Show where a Short URL leads without following it
+//	@Description	Renders the destination, scan verdict, creation date and click count, format=json returns them as JSON
+//	@Description	Previews are not counted as clicks
+//	@Param			link	path	string	true	"Short URL"
+//	@Param			format	query	string	false	"html (default) or json"
+//	@Produce		html
+//	@Produce		json
+//	@Success		200
+//	@Failure		404
+//	@Failure		410
+//	@Failure		451
+//	@Router			/{link}+ [get]
+func PreviewLink(c *fiber.Ctx, queries *database.Queries, ctx context.Context, rdb *redis.Client, ttl time.Duration, geo *geoip.Reader, policy *alias.Policy) error {
+	link := aliasParam(c, "link")
+
+	domainID, err := resolveDomain(ctx, queries, rdb, ttl, c.Hostname())
+	if err != nil {
+		log.Print(err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Internal server error"})
+	}
+
+	data, err := findLink(ctx, queries, policy, domainID, link)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "short url not found"})
+	}
+
+	if isExpired(data) {
+		return c.Status(fiber.StatusGone).JSON(fiber.Map{"error": "short url has expired"})
+	}
+
+	if !data.Enabled {
+		return disabledLink(c, "")
+	}
+
+	// destinations of launch links stay hidden until they go live
+	if isPending(data) {
+		return notLive(c, data, "")
+	}
+
+	if isBlockedCountry(c, geo, data) {
+		return c.Status(fiber.StatusUnavailableForLegalReasons).JSON(fiber.Map{"error": "short url is not available in your country"})
+	}
+
+	preview := newLinkPreview(data)
+
+	if c.Query("format") == "json" {
+		return c.JSON(preview)
+	}
+
+	c.Type("html", "utf-8")
+	return previewPage.Execute(c, preview)
+}
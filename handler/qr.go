@@ -0,0 +1,150 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/url"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/tin3ga/shortly/internal/database"
+	"github.com/tin3ga/shortly/qr"
+)
+
+// qrSource tags visits through a QR code as ?src=qr so they are counted in qr_scans
+const qrSource = "qr"
+
+// isQRScan reports whether the visitor opened the short url from a QR code
+func isQRScan(c *fiber.Ctx) bool {
+	return c.Query("src") == qrSource
+}
+
+// countQRScan adds a visit to the QR scans of the link, failures only skip the count
+func countQRScan(ctx context.Context, queries *database.Queries, data database.Shortly) {
+	if err := queries.CountQRScan(ctx, data.ID); err != nil {
+		log.Print(err)
+	}
+}
+
+// withoutQRSource removes the QR tag from the visitor query so it is never forwarded to the destination
+func withoutQRSource(query string) string {
+	values, err := url.ParseQuery(query)
+	if err != nil || values.Get("src") != qrSource {
+		return query
+	}
+	values.Del("src")
+	return values.Encode()
+}
+
+// qrCodeURL is the public short url of a link tagged as a QR scan, links on a custom domain use its host.
+// Only the scheme and host of URL are kept, its path points at the api while links redirect from the root
+func qrCodeURL(ctx context.Context, queries *database.Queries, baseURL string, data database.Shortly) (string, error) {
+	configured, err := url.Parse(baseURL)
+	if err != nil || configured.Host == "" {
+		return "", errors.New("URL is not configured, cannot build the short url of a QR code")
+	}
+
+	base := &url.URL{Scheme: configured.Scheme, Host: configured.Host}
+	if data.DomainID.Valid {
+		domain, err := queries.GetDomain(ctx, data.DomainID.UUID)
+		if err != nil {
+			return "", err
+		}
+		base.Host = domain.Host
+	}
+
+	link := base.JoinPath(data.ShortLink)
+	link.RawQuery = url.Values{"src": {qrSource}}.Encode()
+	return link.String(), nil
+}
+
+// parseQROptions reads the rendering options from the query, a logo can only be added when the server has one
+func parseQROptions(c *fiber.Ctx, logo *qr.Logo) (qr.Options, error) {
+	opts := qr.DefaultOptions()
+	opts.Format = strings.ToLower(c.Query("format", opts.Format))
+	opts.Size = c.QueryInt("size", opts.Size)
+	opts.Level = strings.ToUpper(c.Query("level", opts.Level))
+	opts.Margin = c.QueryInt("margin", opts.Margin)
+
+	var err error
+	if fg := c.Query("fg"); fg != "" {
+		if opts.Foreground, err = qr.ParseColor(fg); err != nil {
+			return opts, fiber.NewError(fiber.StatusBadRequest, "fg: "+err.Error())
+		}
+	}
+	if bg := c.Query("bg"); bg != "" {
+		if opts.Background, err = qr.ParseColor(bg); err != nil {
+			return opts, fiber.NewError(fiber.StatusBadRequest, "bg: "+err.Error())
+		}
+	}
+
+	if c.QueryBool("logo") {
+		if logo == nil {
+			return opts, fiber.NewError(fiber.StatusBadRequest, "No QR code logo is configured")
+		}
+		opts.Logo = logo
+	}
+
+	if err := opts.Validate(); err != nil {
+		return opts, fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	return opts, nil
+}
+
+// getLinkQRCode Render the QR code of a short URL
+//
+//	@Summary		Render the QR code of a short URL
+//	@Description	Encodes the public short url, on the custom domain of the link when it has one
+//	@Description	The url carries ?src=qr, visits through it are counted in qr_scans and the tag is not forwarded
+//	@Description	A logo always uses error correction level H
+//	@Param			alias	path	string	true	"Short link alias"
+//	@Param			domain	query	string	false	"Custom domain of the link"
+//	@Param			format	query	string	false	"png (default) or svg"
+//	@Param			size	query	int		false	"Width and height in pixels (default 256, 64 to 2048)"
+//	@Param			level	query	string	false	"Error correction level L, M (default), Q or H"
+//	@Param			fg		query	string	false	"Foreground colour as rrggbb or rrggbbaa (default 000000)"
+//	@Param			bg		query	string	false	"Background colour as rrggbb or rrggbbaa (default ffffff)"
+//	@Param			margin	query	int		false	"Quiet zone in modules (default 4, max 16)"
+//	@Param			logo	query	bool	false	"Draw the configured logo over the centre"
+//	@Tags			protected
+//	@Security		BearerAuth
+//	@Produce		png
+//	@Produce		image/svg+xml
+//	@Success		200
+//	@Failure		400
+//	@Failure		404
+//	@Failure		500
+//	@Router			/api/v1/links/{alias}/qr [get]
+func GetLinkQRCode(c *fiber.Ctx, queries *database.Queries, ctx context.Context, baseURL string, logo *qr.Logo) error {
+	opts, err := parseQROptions(c, logo)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	data, err := getOwnedLink(c, queries, ctx, aliasParam(c, "alias"))
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	content, err := qrCodeURL(ctx, queries, baseURL, data)
+	if err != nil {
+		log.Print(err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Cannot render QR code"})
+	}
+
+	image, contentType, err := qr.Render(content, opts)
+	if err != nil {
+		if errors.Is(err, qr.ErrSizeTooSmall) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		}
+		log.Print(err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Cannot render QR code"})
+	}
+
+	log.Printf("Rendering %v QR code of %v", opts.Format, data.ShortLink)
+
+	c.Set(fiber.HeaderContentType, contentType)
+	return c.Send(image)
+}
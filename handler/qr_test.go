@@ -0,0 +1,44 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/tin3ga/shortly/internal/database"
+	"github.com/tin3ga/shortly/qr"
+)
+
+func TestQRCodeURLUsesOrigin(t *testing.T) {
+	data := database.Shortly{ShortLink: "abc"}
+
+	// the samples of .env.sample and .env-docker point URL at the api
+	for _, baseURL := range []string{"http://localhost:8088/api/v1/", "http://localhost:8088/api/v1/lnks/", "http://localhost:8088"} {
+		got, err := qrCodeURL(context.Background(), nil, baseURL, data)
+		if err != nil {
+			t.Fatalf("qrCodeURL(%q): %v", baseURL, err)
+		}
+		if want := "http://localhost:8088/abc?src=qr"; got != want {
+			t.Errorf("qrCodeURL(%q) = %q, want %q", baseURL, got, want)
+		}
+
+		// the code encodes the same content as a code of the redirect url
+		image, _, err := qr.Render(got, qr.DefaultOptions())
+		if err != nil {
+			t.Fatal(err)
+		}
+		want, _, err := qr.Render("http://localhost:8088/abc?src=qr", qr.DefaultOptions())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(image, want) {
+			t.Errorf("QR code of %q does not encode the redirect url", baseURL)
+		}
+	}
+}
+
+func TestQRCodeURLRequiresHost(t *testing.T) {
+	if _, err := qrCodeURL(context.Background(), nil, "", database.Shortly{ShortLink: "abc"}); err == nil {
+		t.Error("qrCodeURL without URL returned no error")
+	}
+}
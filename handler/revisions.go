@@ -0,0 +1,384 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/tin3ga/shortly/alias"
+	"github.com/tin3ga/shortly/internal/database"
+	"github.com/tin3ga/shortly/metadata"
+)
+
+// Edit Link model info
+//
+//	@Description	Edit link Model
+//	@Description	Url, Alias, Redirect_type, Enabled, Activate_at, Forward_query, Utm, Folder, Notes, Tags (omitted fields are left unchanged)
+type EditLinkModel struct {
+	Url   string `json:"url"`
+	Alias string `json:"alias"`
+	// One of 301, 302, 307 or 308
+	Redirect_type int32 `json:"redirect_type"`
+	// Paused links keep their data but stop redirecting
+	Enabled *bool `json:"enabled"`
+	// RFC3339 time or duration from now, an empty value makes the link live immediately
+	Activate_at *string `json:"activate_at"`
+	// Pass the query string of the short url on to the destination
+	Forward_query *bool `json:"forward_query"`
+	// Replaces all UTM parameters, empty fields are removed
+	Utm *UTMModel `json:"utm"`
+	// An empty folder removes the link from its folder
+	Folder *string `json:"folder"`
+	// Free text used by search, empty notes are removed
+	Notes *string `json:"notes"`
+	// Replaces all tags, an empty list removes them
+	Tags []string `json:"tags"`
+}
+
+// updateLink applies update to a link inside a transaction and records the result as a new revision.
+// The state before the first edit is stored as revision 1 so every link can be rolled back to its original.
+func updateLink(ctx context.Context, db *sql.DB, queries *database.Queries, policy *alias.Policy, linkID uuid.UUID, update func(params *database.UpdateLinkParams)) (database.Shortly, database.Shortly, error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return database.Shortly{}, database.Shortly{}, err
+	}
+	defer tx.Rollback()
+
+	qtx := queries.WithTx(tx)
+
+	current, err := qtx.GetLinkForUpdate(ctx, linkID)
+	if err != nil {
+		return database.Shortly{}, database.Shortly{}, err
+	}
+
+	count, err := qtx.CountLinkRevisions(ctx, linkID)
+	if err != nil {
+		return database.Shortly{}, database.Shortly{}, err
+	}
+	if count == 0 {
+		if _, err := qtx.CreateLinkRevision(ctx, revisionParams(current)); err != nil {
+			return database.Shortly{}, database.Shortly{}, err
+		}
+	}
+
+	params := database.UpdateLinkParams{
+		ID:           current.ID,
+		ShortLink:    current.ShortLink,
+		LongLink:     current.LongLink,
+		RedirectType: current.RedirectType,
+		ScanVerdict:  current.ScanVerdict,
+	}
+	update(&params)
+	params.NormalizedLink = nullString(normalizeLink(params.LongLink))
+	params.AliasKey = alias.Key(params.ShortLink)
+	params.AliasKeyUnique = policy.CaseInsensitive
+
+	updated, err := qtx.UpdateLink(ctx, params)
+	if err != nil {
+		return database.Shortly{}, database.Shortly{}, err
+	}
+
+	if _, err := qtx.CreateLinkRevision(ctx, revisionParams(updated)); err != nil {
+		return database.Shortly{}, database.Shortly{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return database.Shortly{}, database.Shortly{}, err
+	}
+
+	return current, updated, nil
+}
+
+func revisionParams(data database.Shortly) database.CreateLinkRevisionParams {
+	return database.CreateLinkRevisionParams{
+		ID:           uuid.New(),
+		LinkID:       data.ID,
+		ShortLink:    data.ShortLink,
+		LongLink:     data.LongLink,
+		RedirectType: data.RedirectType,
+	}
+}
+
+// invalidateCache removes the cached copies of the given links
+func invalidateCache(ctx context.Context, rdb *redis.Client, links ...database.Shortly) {
+	if rdb == nil {
+		return
+	}
+	keys := make([]string, 0, 2*len(links))
+	for _, link := range links {
+		keys = append(keys, cacheKey(link.DomainID, link.ShortLink), cacheKey(link.DomainID, aliasKeyPrefix+link.AliasKey))
+	}
+	if err := rdb.Del(ctx, keys...).Err(); err != nil {
+		log.Print(err)
+	}
+}
+
+// editLink Edit the destination, alias or redirect type of a Short URL
+//
+//	@Summary		Edit a Short URL
+//	@Description	Updates the link and stores the change as a new revision
+//	@Description	Folder, notes and tag changes are not part of the revision history
+//	@Param			alias	path	string			true	"Short URL"
+//	@Param			link	body	EditLinkModel	true	"Fields to change"
+//	@Tags			protected
+//	@Security		BearerAuth
+//	@Produce		json
+//	@Success		200
+//	@Failure		400
+//	@Failure		403
+//	@Failure		404
+//	@Failure		409
+//	@Failure		500
+//	@Router			/api/v1/links/{alias} [patch]
+func EditLink(c *fiber.Ctx, db *sql.DB, queries *database.Queries, ctx context.Context, rdb *redis.Client, apiKey string, fetcher metadata.Fetcher, policy *alias.Policy) error {
+	input := new(EditLinkModel)
+
+	if err := c.BodyParser(input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Cannot parse JSON"})
+	}
+
+	data, err := getOwnedLink(c, queries, ctx, aliasParam(c, "alias"))
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	if input.Redirect_type != 0 && !isValidRedirectType(input.Redirect_type) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "redirect_type must be one of 301, 302, 307 or 308", "redirect_type": input.Redirect_type})
+	}
+
+	input.Alias = alias.Normalize(input.Alias)
+	if err := validateAlias(policy, input.Alias); err != nil {
+		return errorResponse(c, err)
+	}
+
+	// renaming a link to another spelling of its own alias is not a conflict
+	if input.Alias != "" && alias.Key(input.Alias) != data.AliasKey {
+		inUse, err := keyInUse(ctx, queries, policy, data.DomainID, input.Alias)
+		if err != nil {
+			log.Print(err)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Cannot edit short link"})
+		}
+		if inUse {
+			return aliasTaken(c, ctx, queries, policy, data.DomainID, "Alias already in use", input.Alias)
+		}
+	}
+
+	var activateAt sql.NullTime
+	if input.Activate_at != nil {
+		activateAt, err = parseFutureTime("activate_at", *input.Activate_at, time.Now())
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error(), "activate_at": *input.Activate_at})
+		}
+	}
+
+	var utm *utmParams
+	if input.Utm != nil {
+		params, err := newUTMParams(*input.Utm)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		}
+		utm = &params
+	}
+
+	var verdict string
+	if input.Url != "" && input.Url != data.LongLink {
+		if !hasValidScheme(input.Url) {
+			log.Printf("Invalid URL scheme: %v", input.Url)
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "URL must start with https://", "url": input.Url})
+		}
+		if verdict, err = scanLink(apiKey, input.Url); err != nil {
+			return errorResponse(c, err)
+		}
+	}
+
+	updated := data
+	if input.Url != "" || input.Alias != "" || input.Redirect_type != 0 {
+		var previous database.Shortly
+		previous, updated, err = updateLink(ctx, db, queries, policy, data.ID, func(params *database.UpdateLinkParams) {
+			if input.Url != "" && input.Url != params.LongLink {
+				params.LongLink = input.Url
+				params.ScanVerdict = nullString(verdict)
+			}
+			if input.Alias != "" {
+				params.ShortLink = input.Alias
+			}
+			if input.Redirect_type != 0 {
+				params.RedirectType = sql.NullInt32{Int32: input.Redirect_type, Valid: true}
+			}
+		})
+		if err != nil {
+			log.Print(err)
+			if isDuplicateAlias(err) {
+				return aliasTaken(c, ctx, queries, policy, data.DomainID, "Alias already in use", input.Alias)
+			}
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Cannot edit short link"})
+		}
+
+		invalidateCache(ctx, rdb, previous, updated)
+
+		if updated.LongLink != previous.LongLink {
+			fetchLinkMetadata(ctx, queries, fetcher, updated.ID, updated.LongLink)
+		}
+	}
+
+	if input.Enabled != nil {
+		updated, err = queries.SetLinkEnabled(ctx, database.SetLinkEnabledParams{ID: updated.ID, Enabled: *input.Enabled})
+		if err != nil {
+			log.Print(err)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Cannot edit short link"})
+		}
+		invalidateCache(ctx, rdb, updated)
+	}
+
+	if input.Activate_at != nil {
+		updated, err = queries.SetLinkActivation(ctx, database.SetLinkActivationParams{ID: updated.ID, ActivateAt: activateAt})
+		if err != nil {
+			log.Print(err)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Cannot edit short link"})
+		}
+		invalidateCache(ctx, rdb, updated)
+	}
+
+	if input.Forward_query != nil || utm != nil {
+		updated, err = setQueryOptions(ctx, queries, updated, input.Forward_query, utm)
+		if err != nil {
+			log.Print(err)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Cannot edit short link"})
+		}
+		invalidateCache(ctx, rdb, updated)
+	}
+
+	if input.Folder != nil || input.Notes != nil || input.Tags != nil {
+		updated, err = organizeLink(ctx, db, queries, updated, input)
+		if err != nil {
+			return errorResponse(c, err)
+		}
+	}
+
+	tagged, err := withTags(ctx, queries, []database.Shortly{updated})
+	if err != nil {
+		log.Print(err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Cannot fetch tags"})
+	}
+
+	log.Println("Edited a shortened link: ", updated.ShortLink)
+	return c.JSON(fiber.Map{"Success": "Shortened link updated", "Data": tagged[0]})
+}
+
+// getLinkRevisions Fetch the revision history of a Short URL
+//
+//	@Summary		Fetch the revision history of a Short URL
+//	@Description	Returns all revisions, newest first
+//	@Param			alias	path	string	true	"Short URL"
+//	@Tags			protected
+//	@Security		BearerAuth
+//	@Produce		json
+//	@Success		200
+//	@Failure		400
+//	@Failure		404
+//	@Router			/api/v1/links/{alias}/revisions [get]
+func GetLinkRevisions(c *fiber.Ctx, queries *database.Queries, ctx context.Context) error {
+	data, err := getOwnedLink(c, queries, ctx, aliasParam(c, "alias"))
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	revisions, err := queries.GetLinkRevisions(ctx, data.ID)
+	if err != nil {
+		log.Print(err)
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Cannot fetch revisions"})
+	}
+
+	return c.JSON(revisions)
+}
+
+// rollbackLink Roll a Short URL back to an earlier revision
+//
+//	@Summary		Roll a Short URL back to an earlier revision
+//	@Description	Restores the revision and records the rollback as a new revision
+//	@Description	A restored alias must still follow the alias policy and a restored url is scanned again
+//	@Param			alias		path	string	true	"Short URL"
+//	@Param			revision	path	int		true	"Revision number"
+//	@Tags			protected
+//	@Security		BearerAuth
+//	@Produce		json
+//	@Success		200
+//	@Failure		400
+//	@Failure		404
+//	@Failure		409
+//	@Failure		500
+//	@Router			/api/v1/links/{alias}/revisions/{revision}/rollback [post]
+func RollbackLink(c *fiber.Ctx, db *sql.DB, queries *database.Queries, ctx context.Context, rdb *redis.Client, apiKey string, fetcher metadata.Fetcher, policy *alias.Policy) error {
+	revisionNumber, err := strconv.Atoi(c.Params("revision"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid revision number"})
+	}
+
+	data, err := getOwnedLink(c, queries, ctx, aliasParam(c, "alias"))
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	revision, err := queries.GetLinkRevision(ctx, database.GetLinkRevisionParams{LinkID: data.ID, Revision: int32(revisionNumber)})
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "revision not found"})
+	}
+
+	// the revision is checked like an edit, the alias policy or the verdict of the url may have changed since
+	if revision.ShortLink != data.ShortLink {
+		if err := validateAlias(policy, revision.ShortLink); err != nil {
+			return errorResponse(c, err)
+		}
+		if alias.Key(revision.ShortLink) != data.AliasKey {
+			inUse, err := keyInUse(ctx, queries, policy, data.DomainID, revision.ShortLink)
+			if err != nil {
+				log.Print(err)
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Cannot roll back short link"})
+			}
+			if inUse {
+				return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "The alias of this revision is now used by another link", "alias": revision.ShortLink})
+			}
+		}
+	}
+
+	var verdict string
+	if revision.LongLink != data.LongLink {
+		if !hasValidScheme(revision.LongLink) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "URL must start with https://", "url": revision.LongLink})
+		}
+		if verdict, err = scanLink(apiKey, revision.LongLink); err != nil {
+			return errorResponse(c, err)
+		}
+	}
+
+	previous, updated, err := updateLink(ctx, db, queries, policy, data.ID, func(params *database.UpdateLinkParams) {
+		if params.LongLink != revision.LongLink {
+			params.ScanVerdict = nullString(verdict)
+		}
+		params.ShortLink = revision.ShortLink
+		params.LongLink = revision.LongLink
+		params.RedirectType = revision.RedirectType
+	})
+	if err != nil {
+		log.Print(err)
+		if isDuplicateAlias(err) {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "The alias of this revision is now used by another link", "alias": revision.ShortLink})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Cannot roll back short link"})
+	}
+
+	invalidateCache(ctx, rdb, previous, updated)
+
+	if updated.LongLink != previous.LongLink {
+		fetchLinkMetadata(ctx, queries, fetcher, updated.ID, updated.LongLink)
+	}
+
+	log.Printf("Rolled back %v to revision %v", updated.ShortLink, revision.Revision)
+	return c.JSON(fiber.Map{"Success": "Shortened link rolled back", "Data": updated})
+}
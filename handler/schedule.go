@@ -0,0 +1,106 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/tin3ga/shortly/internal/database"
+	"github.com/tin3ga/shortly/targeting"
+)
+
+// Schedule model info
+//
+//	@Description	Weekly destination windows in an IANA timezone such as Europe/Berlin (default UTC)
+//	@Description	Visitors outside every window are sent to the link's url
+type ScheduleModel struct {
+	Timezone string             `json:"timezone"`
+	Windows  []targeting.Window `json:"windows"`
+}
+
+// scheduledDestination returns the destination of the first schedule window open at now, false when none is
+func scheduledDestination(data database.Shortly, now time.Time) (string, bool) {
+	var windows []targeting.Window
+	if err := decodeRules(data.Schedule, &windows); err != nil {
+		log.Print(err)
+	}
+	if len(windows) == 0 {
+		return "", false
+	}
+
+	loc, err := time.LoadLocation(data.ScheduleTimezone)
+	if err != nil {
+		log.Print(err)
+		loc = time.UTC
+	}
+	return targeting.MatchSchedule(windows, loc, now)
+}
+
+// setLinkSchedule Replace the weekly schedule of a Short URL
+//
+//	@Summary		Replace the weekly schedule of a Short URL
+//	@Description	Windows are checked in order after the targeting rules, a window ending before it starts runs past midnight
+//	@Description	An empty list removes the schedule
+//	@Param			alias		path	string			true	"Short URL"
+//	@Param			schedule	body	ScheduleModel	true	"Weekly schedule"
+//	@Tags			protected
+//	@Security		BearerAuth
+//	@Produce		json
+//	@Success		200
+//	@Failure		400
+//	@Failure		404
+//	@Failure		500
+//	@Router			/api/v1/links/{alias}/schedule [put]
+func SetLinkSchedule(c *fiber.Ctx, queries *database.Queries, ctx context.Context, rdb *redis.Client, apiKey string) error {
+	input := new(ScheduleModel)
+
+	if err := c.BodyParser(input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Cannot parse JSON"})
+	}
+
+	windows, timezone, err := targeting.NormalizeSchedule(input.Windows, input.Timezone)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	data, err := getOwnedLink(c, queries, ctx, aliasParam(c, "alias"))
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	for _, window := range windows {
+		if !hasValidScheme(window.Url) {
+			log.Printf("Invalid URL scheme: %v", window.Url)
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "URL must start with https://", "url": window.Url})
+		}
+		if _, err := scanLink(apiKey, window.Url); err != nil {
+			return errorResponse(c, err)
+		}
+	}
+
+	encoded, err := json.Marshal(windows)
+	if err != nil {
+		log.Print(err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Cannot set schedule"})
+	}
+
+	params := database.SetLinkScheduleParams{
+		ID:               data.ID,
+		Schedule:         encoded,
+		ScheduleTimezone: timezone,
+	}
+	updated, err := queries.SetLinkSchedule(ctx, params)
+	if err != nil {
+		log.Print(err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Cannot set schedule"})
+	}
+
+	invalidateCache(ctx, rdb, updated)
+
+	log.Printf("Set %v schedule windows on: %v", len(windows), updated.ShortLink)
+	return c.JSON(fiber.Map{"Success": "Schedule updated", "timezone": timezone, "windows": windows})
+}
@@ -0,0 +1,104 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"html"
+	"log"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"github.com/tin3ga/shortly/internal/database"
+)
+
+const maxSearchQueryLength = 200
+
+// markers set by ts_headline in SearchUserLinks around matching words
+const (
+	headlineStart = "[[["
+	headlineStop  = "]]]"
+)
+
+// highlight escapes a headline for HTML and wraps the matching words in <mark> tags
+func highlight(headline string) string {
+	headline = html.EscapeString(headline)
+	headline = strings.ReplaceAll(headline, headlineStart, "<mark>")
+	return strings.ReplaceAll(headline, headlineStop, "</mark>")
+}
+
+// searchLinks fetches one page of search results and returns the cursor of the next page, empty on the last page
+func searchLinks(ctx context.Context, queries *database.Queries, userID uuid.UUID, query string, limit int32, cursor *linkCursor) ([]database.SearchUserLinksRow, string, error) {
+	params := database.SearchUserLinksParams{
+		Query:     query,
+		UserID:    userID,
+		PageLimit: limit + 1, // one extra row to find out whether there is a next page
+	}
+	if cursor != nil {
+		params.CursorRank = sql.NullFloat64{Float64: float64(cursor.Rank), Valid: true}
+		params.CursorID = uuid.NullUUID{UUID: cursor.ID, Valid: true}
+	}
+
+	results, err := queries.SearchUserLinks(ctx, params)
+	if err != nil {
+		return nil, "", err
+	}
+
+	for i := range results {
+		results[i].Headline = highlight(results[i].Headline)
+	}
+
+	if int32(len(results)) <= limit {
+		return results, "", nil
+	}
+
+	results = results[:limit]
+	last := results[len(results)-1]
+	return results, encodeCursor(linkCursor{Sort: sortRank, ID: last.ID, Rank: last.Rank}), nil
+}
+
+// searchUserLinks Search the links of a user
+//
+//	@Summary		Search the links of a user
+//	@Description	Full-text search over the alias, page title and description, destination host and path, and notes of the user's links
+//	@Description	Results are ranked by relevance, matching words in headline are wrapped in <mark> tags
+//	@Param			q		query	string	true	"Search terms, supports quoted phrases, or and -"
+//	@Param			limit	query	int		false	"Page size (default 50, max 200)"
+//	@Param			cursor	query	string	false	"next_cursor of the previous page"
+//	@Tags			protected
+//	@Security		BearerAuth
+//	@Produce		json
+//	@Success		200
+//	@Failure		400
+//	@Failure		500
+//	@Router			/api/v1/links/search [get]
+func SearchUserLinks(c *fiber.Ctx, queries *database.Queries, ctx context.Context) error {
+	query := strings.TrimSpace(c.Query("q"))
+	if query == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "q is required"})
+	}
+	if len(query) > maxSearchQueryLength {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "q is too long", "max_length": maxSearchQueryLength})
+	}
+
+	limit, cursor, err := parsePage(c, sortRank)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	userID, err := getUserID(c)
+	if err != nil {
+		log.Printf("Error parsing UserID: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"success": false, "message": "Invalid UserID format"})
+	}
+
+	results, next, err := searchLinks(ctx, queries, userID, query, limit, cursor)
+	if err != nil {
+		log.Print(err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Cannot search links"})
+	}
+
+	log.Printf("Searched user links, %v results", len(results))
+	return c.JSON(pageResponse(results, next))
+}
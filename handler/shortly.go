@@ -2,7 +2,10 @@ package handler
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
 	"strings"
 	"time"
@@ -11,57 +14,349 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/redis/go-redis/v9"
-	"github.com/thanhpk/randstr"
 	"github.com/tin3ga/urlscan"
 
+	"github.com/tin3ga/shortly/alias"
+	"github.com/tin3ga/shortly/geoip"
 	"github.com/tin3ga/shortly/internal/database"
+	"github.com/tin3ga/shortly/metadata"
 )
 
 // Shorten Link model info
 //
 //	@Description	Shorten link Model
-//	@Description	Url, Custom_alias
+//	@Description	Url, Custom_alias, Alias_strategy, Expires_at, Activate_at, Max_clicks, Password, Redirect_type, Forward_query, Utm, Reuse_existing, Domain, Folder, Notes, Tags
 type ShortenLinkModel struct {
-	Url          string `json:"url"`
+	Url string `json:"url"`
+	// Letters of any script, digits, emoji, - and _ within the configured length, reserved words, profanity
+	// and look-alike letters mixing scripts are rejected
 	Custom_alias string `json:"custom_alias"`
-	// expiration_date string `json:"expiration_date"`
-
+	// RFC3339 time (2025-12-31T23:59:59Z) or a duration from now (72h)
+	Expires_at string `json:"expires_at"`
+	// The link only starts redirecting at this RFC3339 time or duration from now
+	Activate_at string `json:"activate_at"`
+	// Stop redirecting after this many clicks, 1 creates a one-time link
+	Max_clicks int32 `json:"max_clicks"`
+	// Visitors must enter this password before being redirected
+	Password string `json:"password"`
+	// One of 301, 302, 307 or 308, the server default is used when omitted
+	Redirect_type int32 `json:"redirect_type"`
+	// Pass the query string of the short url on to the destination
+	Forward_query bool `json:"forward_query"`
+	// Added to the destination on every redirect, overriding parameters of the same name
+	Utm UTMModel `json:"utm"`
+	// One of random, sequence or words, the server default is used when omitted
+	Alias_strategy string `json:"alias_strategy"`
+	// Return the newest live link already pointing at the same url and without any option instead of creating
+	// a new one, ignored when any other option than the domain or alias strategy is given
+	Reuse_existing bool `json:"reuse_existing"`
+	// Verified custom domain of the user, empty for the default domain
+	Domain string   `json:"domain"`
+	Folder string   `json:"folder"`
+	Notes  string   `json:"notes"`
+	Tags   []string `json:"tags"`
 }
 
 // Delete Link model info
 //
 //	@Description	Delete Link Model
-//	@Description	Url
+//	@Description	Url, Domain
 type DeleteLinkModel struct {
 	Url string `json:"url"`
+	// Custom domain of the link, empty for the default domain
+	Domain string `json:"domain"`
+}
+
+// parseFutureTime converts an absolute RFC3339 time or a relative duration into a time after now,
+// field names the value in errors. An empty value means no time is set.
+func parseFutureTime(field, value string, now time.Time) (sql.NullTime, error) {
+	if value == "" {
+		return sql.NullTime{}, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		duration, durationErr := time.ParseDuration(value)
+		if durationErr != nil {
+			return sql.NullTime{}, fmt.Errorf("%v must be an RFC3339 time or a duration such as 72h", field)
+		}
+		t = now.Add(duration)
+	}
+
+	if !t.After(now) {
+		return sql.NullTime{}, fmt.Errorf("%v must be in the future", field)
+	}
+
+	return sql.NullTime{Time: t.UTC(), Valid: true}, nil
+}
+
+// isExpired reports whether the link has passed its expiry time
+func isExpired(data database.Shortly) bool {
+	return data.ExpiresAt.Valid && !data.ExpiresAt.Time.After(time.Now())
+}
+
+// isPending reports whether the link has not reached its activation time yet
+func isPending(data database.Shortly) bool {
+	return data.ActivateAt.Valid && data.ActivateAt.Time.After(time.Now())
+}
+
+// notLive answers visits to a link before its activation time, visitors are redirected to notLiveURL when it is set
+func notLive(c *fiber.Ctx, data database.Shortly, notLiveURL string) error {
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	if notLiveURL != "" {
+		return c.Redirect(notLiveURL, fiber.StatusFound)
+	}
+	return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "short url is not live yet", "activate_at": data.ActivateAt.Time})
+}
+
+// errClickLimitReached is returned by claimClick once a link has used up its max_clicks
+var errClickLimitReached = errors.New("click limit reached")
+
+// claimClick atomically counts a click in Postgres, refusing it once the link reached max_clicks
+func claimClick(ctx context.Context, queries *database.Queries, data database.Shortly) error {
+	_, err := queries.ClaimClick(ctx, data.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return errClickLimitReached
+	}
+	return err
+}
+
+// hasValidScheme reports whether the url uses https
+func hasValidScheme(link string) bool {
+	return strings.HasPrefix(link, "https://")
+}
+
+// scanLink asks the external API for a verdict on the url, failures are returned as a *fiber.Error
+func scanLink(apiKey string, link string) (string, error) {
+	result, err := urlscan.Scan(apiKey, link)
+	if err != nil {
+		if err.Error() == "API error" {
+			log.Print(err)
+			return "", fiber.NewError(fiber.StatusBadRequest, "Check that url is valid / try again later:)")
+		}
+		if err.Error() == "authentication failed, make sure your API Key is valid" {
+			return "", fiber.NewError(fiber.StatusBadRequest, "External API error, make sure your API Key is valid:)")
+		}
+		log.Print(err)
+		return "", fiber.NewError(fiber.StatusInternalServerError, "External API error, try again later:)")
+	}
+
+	// Handle malicious URL detection
+	if result == "malicious" {
+		log.Printf("Malicious url detected: %v", link)
+		return result, fiber.NewError(fiber.StatusForbidden, "Url is malicious")
+	}
+
+	log.Printf("URL provided: %v is %v", link, result)
+	return result, nil
+}
+
+// validateAlias checks a custom alias against the alias policy, an empty alias is generated instead.
+// The policy's charset also keeps out the + suffix that opens the preview page.
+func validateAlias(policy *alias.Policy, custom string) error {
+	if custom == "" {
+		return nil
+	}
+	return policy.Check(custom)
+}
+
+// isDuplicateAlias reports whether err was caused by an alias that is already taken
+func isDuplicateAlias(err error) bool {
+	msg := err.Error()
+	return strings.Contains(msg, "unique_short_link") || strings.Contains(msg, "unique_domain_short_link") ||
+		strings.Contains(msg, "unique_alias_key")
+}
+
+// isValidRedirectType reports whether status is a redirect a link may use
+func isValidRedirectType(status int32) bool {
+	switch status {
+	case fiber.StatusMovedPermanently, fiber.StatusFound, fiber.StatusTemporaryRedirect, fiber.StatusPermanentRedirect:
+		return true
+	}
+	return false
+}
+
+// defaultRedirectMaxAge is how long browsers may cache a permanent redirect when redirect_max_age is not set
+const defaultRedirectMaxAge = time.Hour
+
+// redirectStatus returns the redirect status code used for the link, links without one use defaultType
+func redirectStatus(data database.Shortly, defaultType int) int {
+	if data.RedirectType.Valid {
+		return int(data.RedirectType.Int32)
+	}
+	if isValidRedirectType(int32(defaultType)) {
+		return defaultType
+	}
+	return fiber.StatusMovedPermanently
+}
+
+// redirectCacheControl lets browsers keep permanent redirects for maxAge. Temporary redirects are revalidated
+// on every visit and links that stop working on their own (expiry or click limit), run a split test or follow a
+// schedule are never stored.
+// Redirects that depend on the visitor's country are only kept by the browser, never by shared caches.
+func redirectCacheControl(data database.Shortly, status int, maxAge time.Duration) string {
+	if data.ExpiresAt.Valid || data.MaxClicks.Valid || hasRules(data.Variants) || hasRules(data.Schedule) {
+		return "no-store"
+	}
+	if status == fiber.StatusMovedPermanently || status == fiber.StatusPermanentRedirect {
+		if maxAge <= 0 {
+			maxAge = defaultRedirectMaxAge
+		}
+		scope := "public"
+		if hasRules(data.GeoRules) || len(data.BlockedCountries) > 0 {
+			scope = "private"
+		}
+		return fmt.Sprintf("%v, max-age=%d", scope, int(maxAge.Seconds()))
+	}
+	return "no-cache"
+}
+
+// redirect sends the visitor to the destination with the redirect type and cache headers of the link
+func redirect(c *fiber.Ctx, ctx context.Context, queries *database.Queries, geo *geoip.Reader, data database.Shortly, defaultType int, maxAge time.Duration) error {
+	status := redirectStatus(data, defaultType)
+	c.Set(fiber.HeaderCacheControl, redirectCacheControl(data, status, maxAge))
+
+	destination := visitorDestination(c, ctx, queries, geo, data)
+	log.Println("Redirecting to: ", destination)
+	return c.Redirect(destination, status)
+}
+
+// getOwnedLink fetches a link by its alias and checks that it belongs to the authenticated user.
+// Links on a custom domain are selected with the ?domain= query parameter.
+func getOwnedLink(c *fiber.Ctx, queries *database.Queries, ctx context.Context, alias string) (database.Shortly, error) {
+	userID, err := getUserID(c)
+	if err != nil {
+		log.Printf("Error parsing UserID: %v", err)
+		return database.Shortly{}, fiber.NewError(fiber.StatusBadRequest, "Invalid UserID format")
+	}
+
+	domainID, err := ownedDomainID(c, queries, ctx, c.Query("domain"))
+	if err != nil {
+		return database.Shortly{}, err
+	}
+
+	data, err := lookupLink(ctx, queries, domainID, alias)
+	if err != nil || data.UserID != userID {
+		return database.Shortly{}, fiber.NewError(fiber.StatusNotFound, "short url not found")
+	}
+
+	return data, nil
+}
+
+// createLink inserts a link and its tags in one transaction
+func createLink(ctx context.Context, db *sql.DB, queries *database.Queries, params database.CreateShortLinkParams, tags []string) (database.Shortly, error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return database.Shortly{}, err
+	}
+	defer tx.Rollback()
+
+	qtx := queries.WithTx(tx)
+
+	data, err := qtx.CreateShortLink(ctx, params)
+	if err != nil {
+		return database.Shortly{}, err
+	}
+
+	if err := setLinkTags(ctx, qtx, data.UserID, data.ID, tags); err != nil {
+		return database.Shortly{}, err
+	}
+
+	return data, tx.Commit()
+}
+
+// createGeneratedLink creates a link under an alias from generator, an alias that is already taken is
+// replaced by a new one at most alias.MaxAttempts times
+func createGeneratedLink(ctx context.Context, db *sql.DB, queries *database.Queries, generator alias.Generator, policy *alias.Policy, params database.CreateShortLinkParams, tags []string) (database.Shortly, error) {
+	for attempt := 1; ; attempt++ {
+		shortLink, err := generator.Generate(ctx)
+		if err != nil {
+			return database.Shortly{}, err
+		}
+		params.ShortLink = shortLink
+		params.AliasKey = alias.Key(shortLink)
+
+		inUse, err := keyInUse(ctx, queries, policy, params.DomainID, shortLink)
+		if err != nil {
+			return database.Shortly{}, err
+		}
+
+		var data database.Shortly
+		if !inUse {
+			data, err = createLink(ctx, db, queries, params, tags)
+			if err == nil || !isDuplicateAlias(err) {
+				return data, err
+			}
+		}
+		if attempt == alias.MaxAttempts {
+			return database.Shortly{}, fmt.Errorf("no free alias after %d attempts", alias.MaxAttempts)
+		}
+		log.Printf("Generated alias %v is taken, retrying", shortLink)
+	}
+}
+
+// errorResponse writes a *fiber.Error as a JSON error, any other error becomes a 500
+func errorResponse(c *fiber.Ctx, err error) error {
+	var policyErr *alias.PolicyError
+	if errors.As(err, &policyErr) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "alias is not allowed", "alias": policyErr.Alias, "violations": policyErr.Violations})
+	}
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		return c.Status(fiberErr.Code).JSON(fiber.Map{"error": fiberErr.Message})
+	}
+	log.Print(err)
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Internal server error"})
 }
 
 // getLinks Fetch all links
 //
 //	@Summary		Fetch all links
-//	@Description	Returns all links
+//	@Description	Returns one page of links and the cursor of the next page
+//	@Param			limit				query	int		false	"Page size (default 50, max 200)"
+//	@Param			cursor				query	string	false	"next_cursor of the previous page"
+//	@Param			sort				query	string	false	"created_at (default), click_count or alias"
+//	@Param			created_from		query	string	false	"Created at or after this RFC3339 time or date"
+//	@Param			created_to			query	string	false	"Created before this RFC3339 time or on or before this date"
+//	@Param			destination_host	query	string	false	"Only links pointing to this host"
 //	@Produce		json
 //	@Success		200
+//	@Failure		400
 //	@Router			/api/v1/links/all [get]
 func GetLinks(c *fiber.Ctx, queries *database.Queries, ctx context.Context) error {
-	data, err := queries.GetLinks(ctx)
+	listing, err := parseLinkListing(c)
 	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	data, next, err := listLinks(ctx, queries, listing)
+	if err != nil {
+		log.Print(err)
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Cannot fetch links"})
 	}
 	log.Println("Fetching links")
 
-	return c.JSON(data)
+	return c.JSON(pageResponse(data, next))
 
 }
 
 // getUserLinks Fetch all links associated to a user
 //
 //	@Summary		Fetch all user links
-//	@Description	Returns all user links
+//	@Description	Returns one page of user links with their tags and the cursor of the next page
+//	@Param			limit				query	int		false	"Page size (default 50, max 200)"
+//	@Param			cursor				query	string	false	"next_cursor of the previous page"
+//	@Param			sort				query	string	false	"created_at (default), click_count or alias"
+//	@Param			created_from		query	string	false	"Created at or after this RFC3339 time or date"
+//	@Param			created_to			query	string	false	"Created before this RFC3339 time or on or before this date"
+//	@Param			destination_host	query	string	false	"Only links pointing to this host"
+//	@Param			tag					query	string	false	"Only links with this tag"
+//	@Param			folder				query	string	false	"Only links in this folder"
 //	@Tags			protected
 //	@Security		BearerAuth
 //	@Produce		json
 //	@Success		200
+//	@Failure		400
 //	@Router			/api/v1/links/userlinks [get]
 func GetUserLinks(c *fiber.Ctx, queries *database.Queries, ctx context.Context) error {
 
@@ -77,36 +372,75 @@ func GetUserLinks(c *fiber.Ctx, queries *database.Queries, ctx context.Context)
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"success": false, "message": "Invalid UserID format"})
 	}
 
-	data, err := queries.GetUserLinks(ctx, userID)
+	listing, err := parseLinkListing(c)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+	listing.UserID = uuid.NullUUID{UUID: userID, Valid: true}
+
+	if folder := c.Query("folder"); folder != "" {
+		listing.Folder = sql.NullString{String: folder, Valid: true}
+	}
+	if tag := c.Query("tag"); tag != "" {
+		name, err := normalizeTag(tag)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error(), "tag": tag})
+		}
+		listing.Tag = sql.NullString{String: name, Valid: true}
+	}
+
+	links, next, err := listLinks(ctx, queries, listing)
+
+	if err != nil {
+		log.Print(err)
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Cannot fetch links"})
+	}
 
+	data, err := withTags(ctx, queries, links)
 	if err != nil {
+		log.Print(err)
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Cannot fetch links"})
 	}
 
 	log.Println("Fetching User links")
 
-	return c.Status(fiber.StatusOK).JSON(data)
+	return c.Status(fiber.StatusOK).JSON(pageResponse(data, next))
 
 }
 
 // getLink Fetch a Original URL by Short URL
 //
 //	@Summary		Fetch a Original URL by Short URL
-//	@Description	Redirects to the original URL
+//	@Description	Redirects to the original URL, password protected links serve an unlock form instead
 //	@Param			link	path	string	true	"Redirects to Original URL"
+//	@Description	Uses the redirect type of the link or the server default, permanent redirects may be cached by browsers for redirect_max_age
+//	@Success		200
 //	@Success		301
+//	@Success		302
+//	@Success		307
+//	@Success		308
 //	@Failure		404
+//	@Failure		410
+//	@Failure		451
 //	@Router			/{link} [get]
-func GetLink(c *fiber.Ctx, queries *database.Queries, ctx context.Context, rdb *redis.Client, ttl time.Duration) error {
-	link := c.Params("link")
+func GetLink(c *fiber.Ctx, queries *database.Queries, ctx context.Context, rdb *redis.Client, ttl time.Duration, redirectType int, maxAge time.Duration, geo *geoip.Reader, notLiveURL, disabledURL string, policy *alias.Policy) error {
+	link := aliasParam(c, "link")
+
+	// custom domains are resolved from the Host header, any other host serves the default domain
+	domainID, err := resolveDomain(ctx, queries, rdb, ttl, c.Hostname())
+	if err != nil {
+		log.Print(err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Internal server error"})
+	}
+	key := linkCacheKey(policy, domainID, link)
 
 	// caching - Get
 
 	// check if rdb is not nil to prevent nil pointer dereference error
 	if rdb != nil {
-		val, err := rdb.Get(ctx, link).Result()
+		val, err := rdb.Get(ctx, key).Result()
 		if err != nil {
-			log.Printf("Cannot find data with key: %s", link)
+			log.Printf("Cannot find data with key: %s", key)
 		}
 
 		if val != "" {
@@ -118,8 +452,35 @@ func GetLink(c *fiber.Ctx, queries *database.Queries, ctx context.Context, rdb *
 				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Internal server error"})
 			}
 
-			log.Println("Redirecting to: ", data.LongLink)
-			return c.Redirect(data.LongLink, fiber.StatusMovedPermanently)
+			if isExpired(data) {
+				return c.Status(fiber.StatusGone).JSON(fiber.Map{"error": "short url has expired"})
+			}
+
+			if !data.Enabled {
+				return disabledLink(c, disabledURL)
+			}
+
+			if isPending(data) {
+				return notLive(c, data, notLiveURL)
+			}
+
+			if isBlockedCountry(c, geo, data) {
+				return c.Status(fiber.StatusUnavailableForLegalReasons).JSON(fiber.Map{"error": "short url is not available in your country"})
+			}
+
+			// capped links are always counted in Postgres so the cache cannot exceed max_clicks
+			if data.MaxClicks.Valid {
+				if err := claimClick(ctx, queries, data); err != nil {
+					if errors.Is(err, errClickLimitReached) {
+						rdb.Del(ctx, key)
+						return c.Status(fiber.StatusGone).JSON(fiber.Map{"error": "short url has reached its click limit"})
+					}
+					log.Print(err)
+					return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Internal server error"})
+				}
+			}
+
+			return redirect(c, ctx, queries, geo, data, redirectType, maxAge)
 
 		}
 
@@ -127,21 +488,64 @@ func GetLink(c *fiber.Ctx, queries *database.Queries, ctx context.Context, rdb *
 	// end caching - Get
 
 	// check if value in database, returns if no data is found skips caching set
-	data, err := queries.GetLongLink(ctx, link)
+	data, err := findLink(ctx, queries, policy, domainID, link)
 	if err != nil {
+		// expired links that were archived by the reaper are still reported as gone
+		archiveParams := database.GetArchivedLinkParams{ShortLink: alias.Normalize(link), DomainID: domainID}
+		if _, archiveErr := queries.GetArchivedLink(ctx, archiveParams); archiveErr == nil {
+			return c.Status(fiber.StatusGone).JSON(fiber.Map{"error": "short url has expired"})
+		}
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "short url not found"})
 	}
 
+	if isExpired(data) {
+		return c.Status(fiber.StatusGone).JSON(fiber.Map{"error": "short url has expired"})
+	}
+
+	if !data.Enabled {
+		return disabledLink(c, disabledURL)
+	}
+
+	if isPending(data) {
+		return notLive(c, data, notLiveURL)
+	}
+
+	if isBlockedCountry(c, geo, data) {
+		return c.Status(fiber.StatusUnavailableForLegalReasons).JSON(fiber.Map{"error": "short url is not available in your country"})
+	}
+
+	// protected links are never cached, the unlock form posts back to UnlockLink
+	if data.PasswordHash.Valid {
+		return renderUnlockPage(c, fiber.StatusOK, link, "")
+	}
+
+	// increment click count by one, refused once max_clicks is reached
+
+	if err := claimClick(ctx, queries, data); err != nil {
+		if errors.Is(err, errClickLimitReached) {
+			return c.Status(fiber.StatusGone).JSON(fiber.Map{"error": "short url has reached its click limit"})
+		}
+		log.Print(err)
+		if data.MaxClicks.Valid {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Internal server error"})
+		}
+	}
+
 	// caching - Set
-	// cache results if data exists key is short url/link
+	// cache results if data exists key is the domain and short url/link
 	if rdb != nil {
 		marshalData, err := json.Marshal(data)
 		if err != nil {
 			log.Print(err)
 		}
+		// never keep a link in cache past its expiry time
+		cacheTTL := ttl
+		if data.ExpiresAt.Valid && (cacheTTL == 0 || time.Until(data.ExpiresAt.Time) < cacheTTL) {
+			cacheTTL = time.Until(data.ExpiresAt.Time)
+		}
 		// add a item to cache if it did not exist
 
-		err = rdb.Set(ctx, link, marshalData, ttl).Err()
+		err = rdb.Set(ctx, key, marshalData, cacheTTL).Err()
 		if err != nil {
 			log.Print(err)
 		} else {
@@ -150,14 +554,7 @@ func GetLink(c *fiber.Ctx, queries *database.Queries, ctx context.Context, rdb *
 
 	}
 
-	// increment click count by one
-
-	if err := queries.IncrementClickCount(ctx, link); err != nil {
-		log.Print(err)
-	}
-
-	log.Println("Redirecting to: ", data.LongLink)
-	return c.Redirect(data.LongLink, fiber.StatusMovedPermanently)
+	return redirect(c, ctx, queries, geo, data, redirectType, maxAge)
 
 }
 
@@ -165,15 +562,18 @@ func GetLink(c *fiber.Ctx, queries *database.Queries, ctx context.Context, rdb *
 //
 //	@Summary		Insert an entry for a Short URL and Long URL
 //	@Description	Returns a Short URL
+//	@Description	A repeated request with the same Idempotency-Key within 24h gets the first response back
 //	@Param			shorten_link	body	ShortenLinkModel	true	"Shorten a Link (custom alias is optional)"
+//	@Param			Idempotency-Key	header	string				false	"Unique key of the request, safe to retry with"
 //	@Tags			protected
 //	@Security		BearerAuth
 //	@Success		200
 //	@Failure		400
 //	@Failure		403
+//	@Failure		409
 //	@Failure		500
 //	@Router			/api/v1/links/shorten [post]
-func ShortenLink(c *fiber.Ctx, queries *database.Queries, ctx context.Context, apiKey string) error {
+func ShortenLink(c *fiber.Ctx, db *sql.DB, queries *database.Queries, ctx context.Context, apiKey string, fetcher metadata.Fetcher, aliases *alias.Generators, policy *alias.Policy) error {
 	url := new(ShortenLinkModel)
 
 	if err := c.BodyParser(url); err != nil {
@@ -181,7 +581,7 @@ func ShortenLink(c *fiber.Ctx, queries *database.Queries, ctx context.Context, a
 	}
 
 	// Check if the URL starts with "https"
-	if !strings.HasPrefix(url.Url, "https://") {
+	if !hasValidScheme(url.Url) {
 		log.Printf("Invalid URL scheme: %v", url.Url)
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"error": "URL must start with https://",
@@ -190,37 +590,100 @@ func ShortenLink(c *fiber.Ctx, queries *database.Queries, ctx context.Context, a
 
 	}
 
-	// Validate the URL using the external API
-	result, err := urlscan.Scan(apiKey, url.Url)
+	expiresAt, err := parseFutureTime("expires_at", url.Expires_at, time.Now())
 	if err != nil {
-		if err.Error() == "API error" {
-			log.Print(err)
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Check that url is valid / try again later:)", "url": url.Url})
-		}
-		if err.Error() == "authentication failed, make sure your API Key is valid" {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "External API error, make sure your API Key is valid:)"})
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error(), "expires_at": url.Expires_at})
+	}
 
-		}
-		log.Print(err)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "External API error, try again later:)"})
+	activateAt, err := parseFutureTime("activate_at", url.Activate_at, time.Now())
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error(), "activate_at": url.Activate_at})
+	}
+	if activateAt.Valid && expiresAt.Valid && !activateAt.Time.Before(expiresAt.Time) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "activate_at must be before expires_at"})
+	}
 
+	if url.Max_clicks < 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "max_clicks must be a positive number", "max_clicks": url.Max_clicks})
 	}
+	maxClicks := sql.NullInt32{Int32: url.Max_clicks, Valid: url.Max_clicks > 0}
 
-	// Handle malicious URL detection
-	if result == "malicious" {
-		log.Printf("Malicious url detected: %v", url.Url)
-		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "Url is malicious", "url": url.Url})
+	if url.Redirect_type != 0 && !isValidRedirectType(url.Redirect_type) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "redirect_type must be one of 301, 302, 307 or 308", "redirect_type": url.Redirect_type})
+	}
+	redirectType := sql.NullInt32{Int32: url.Redirect_type, Valid: url.Redirect_type != 0}
 
-	} else {
-		log.Printf("URL provided: %v is %v", url.Url, result)
+	utm, err := newUTMParams(url.Utm)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
 	}
 
-	var ShortLink string
+	folder, err := normalizeFolder(url.Folder)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error(), "folder": url.Folder})
+	}
 
-	if url.Custom_alias == "" {
-		ShortLink = randstr.Hex(8) // Generate a random 8 character string
-	} else {
-		ShortLink = url.Custom_alias
+	notes, err := normalizeNotes(url.Notes)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	tags, err := normalizeTags(url.Tags)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error(), "tags": url.Tags})
+	}
+
+	domainID, err := verifiedDomainID(c, queries, ctx, url.Domain)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	var passwordHash sql.NullString
+	if url.Password != "" {
+		hash, err := hashPassword(url.Password)
+		if err != nil {
+			log.Print(err)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Cannot create short link"})
+		}
+		passwordHash = sql.NullString{String: hash, Valid: true}
+	}
+
+	url.Custom_alias = alias.Normalize(url.Custom_alias)
+	if err := validateAlias(policy, url.Custom_alias); err != nil {
+		return errorResponse(c, err)
+	}
+
+	generator, err := aliases.Get(url.Alias_strategy)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error(), "alias_strategy": url.Alias_strategy})
+	}
+
+	// repeated submissions of the same url get the link created the first time
+	if canReuse(url) {
+		userID, err := getUserID(c)
+		if err != nil {
+			log.Printf("Error parsing UserID: %v", err)
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"success": false, "message": "Invalid UserID format"})
+		}
+		existing, found, err := findReusableLink(ctx, queries, userID, url.Url, domainID)
+		if err != nil {
+			log.Print(err)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Cannot create short link"})
+		}
+		if found {
+			log.Println("Reused a shortened link: ", existing.ShortLink)
+			return c.JSON(fiber.Map{"Success": "Existing shortened link returned", "url link": existing.ShortLink})
+		}
+	}
+
+	// Validate the URL using the external API
+	verdict, err := scanLink(apiKey, url.Url)
+	if err != nil {
+		var fiberErr *fiber.Error
+		if errors.As(err, &fiberErr) {
+			return c.Status(fiberErr.Code).JSON(fiber.Map{"error": fiberErr.Message, "url": url.Url})
+		}
+		return errorResponse(c, err)
 	}
 
 	LongLink := url.Url
@@ -272,28 +735,64 @@ func ShortenLink(c *fiber.Ctx, queries *database.Queries, ctx context.Context, a
 	}
 
 	params := database.CreateShortLinkParams{
-		ID:        uuidUser,
-		UserID:    userID,
-		ShortLink: ShortLink,
-		LongLink:  LongLink,
+		ID:             uuidUser,
+		UserID:         userID,
+		ShortLink:      url.Custom_alias,
+		AliasKey:       alias.Key(url.Custom_alias),
+		AliasKeyUnique: policy.CaseInsensitive,
+		LongLink:       LongLink,
+		ExpiresAt:      expiresAt,
+		MaxClicks:      maxClicks,
+		PasswordHash:   passwordHash,
+		DomainID:       domainID,
+		Folder:         folder,
+		Notes:          notes,
+		ScanVerdict:    nullString(verdict),
+		RedirectType:   redirectType,
+		ForwardQuery:   url.Forward_query,
+		UtmSource:      utm.Source,
+		UtmMedium:      utm.Medium,
+		UtmCampaign:    utm.Campaign,
+		UtmTerm:        utm.Term,
+		UtmContent:     utm.Content,
+		ActivateAt:     activateAt,
+		NormalizedLink: nullString(normalizeLink(LongLink)),
+	}
+	if url.Custom_alias != "" {
+		inUse, err := keyInUse(ctx, queries, policy, domainID, url.Custom_alias)
+		if err != nil {
+			log.Print(err)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Cannot create short link"})
+		}
+		if inUse {
+			return aliasTaken(c, ctx, queries, policy, domainID, "Duplicate short link, create a new alias", url.Custom_alias)
+		}
+	}
+
+	var data database.Shortly
+	if url.Custom_alias == "" {
+		data, err = createGeneratedLink(ctx, db, queries, generator, policy, params, tags)
+	} else {
+		data, err = createLink(ctx, db, queries, params, tags)
 	}
-	_, err = queries.CreateShortLink(ctx, params)
 	if err != nil {
 		log.Print(err)
-		if err.Error() == "pq: duplicate key value violates unique constraint \"unique_short_link\"" {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Duplicate short link, create a new alias"})
+		if url.Custom_alias != "" && isDuplicateAlias(err) {
+			return aliasTaken(c, ctx, queries, policy, domainID, "Duplicate short link, create a new alias", url.Custom_alias)
 		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Cannot create short link"})
 	}
-	log.Println("Created a shortened link: ", ShortLink)
+	log.Println("Created a shortened link: ", data.ShortLink)
 
-	return c.JSON(fiber.Map{"Success": "Shortened link created", "url link": ShortLink})
+	fetchLinkMetadata(ctx, queries, fetcher, data.ID, data.LongLink)
+
+	return c.JSON(fiber.Map{"Success": "Shortened link created", "url link": data.ShortLink})
 }
 
 // deleteLink Delete url data by short url
 //
 //	@Summary		Delete url data by short url
-//	@Description	Returns a success message
+//	@Description	Moves the link to the trash, it can be restored until the trash retention has passed
 //	@Param			url	body	DeleteLinkModel	true	"Delete a Link"
 //	@Tags			protected
 //	@Security		BearerAuth
@@ -302,25 +801,39 @@ func ShortenLink(c *fiber.Ctx, queries *database.Queries, ctx context.Context, a
 //	@Failure		404
 //	@Failure		500
 //	@Router			/api/v1/links/shorten [delete]
-func DeleteLink(c *fiber.Ctx, queries *database.Queries, ctx context.Context) error {
+func DeleteLink(c *fiber.Ctx, queries *database.Queries, ctx context.Context, rdb *redis.Client) error {
 	url := new(DeleteLinkModel)
 
 	if err := c.BodyParser(url); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Cannot parse JSON"})
 	}
 
-	// Check if the short link exists
-	_, err := queries.GetLongLink(ctx, url.Url)
+	userID, err := getUserID(c)
+	if err != nil {
+		log.Printf("Error parsing UserID: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"success": false, "message": "Invalid UserID format"})
+	}
+
+	domainID, err := ownedDomainID(c, queries, ctx, url.Domain)
 	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	// Check if the short link exists, links of other users are reported as missing like in getOwnedLink
+	data, err := lookupLink(ctx, queries, domainID, url.Url)
+	if err != nil || data.UserID != userID {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "short url not found"})
 	}
 
-	err = queries.DeleteLink(ctx, url.Url)
+	trashed, err := queries.TrashLink(ctx, data.ID)
 	if err != nil {
+		log.Print(err)
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Cannot delete short link"})
 	}
 
-	log.Println("Deleted a shortened link: ", url.Url)
-	return c.JSON(fiber.Map{"Success": "Shortened link deleted"})
+	invalidateCache(ctx, rdb, trashed)
+
+	log.Println("Moved a shortened link to the trash: ", url.Url)
+	return c.JSON(fiber.Map{"Success": "Shortened link moved to the trash"})
 
 }
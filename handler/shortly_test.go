@@ -0,0 +1,318 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/tin3ga/shortly/alias"
+	"github.com/tin3ga/shortly/internal/database"
+)
+
+const testJWTSecret = "test-secret"
+
+// redirectTestConfig holds the redirect settings of a test app
+type redirectTestConfig struct {
+	defaultType int
+	notLiveURL  string
+	disabledURL string
+}
+
+// newLinkTestApp registers the redirect, edit, rollback and shorten routes like router.SetupRoutes does, the
+// shorten route has no alias generators and only serves requests that fail validation
+func newLinkTestApp(t *testing.T, db *sql.DB, queries *database.Queries, rdb *redis.Client, cfg redirectTestConfig) *fiber.App {
+	t.Helper()
+	t.Setenv("jwt_secret", testJWTSecret)
+
+	policy, err := alias.NewPolicy(0, 0, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.defaultType == 0 {
+		cfg.defaultType = fiber.StatusFound
+	}
+
+	ctx := context.Background()
+	app := fiber.New()
+	app.Get("/:link", func(c *fiber.Ctx) error {
+		return GetLink(c, queries, ctx, rdb, time.Hour, cfg.defaultType, 0, nil, cfg.notLiveURL, cfg.disabledURL, policy)
+	})
+	app.Patch("/api/v1/links/:alias", func(c *fiber.Ctx) error {
+		return EditLink(c, db, queries, ctx, rdb, "", nil, policy)
+	})
+	app.Post("/api/v1/links/:alias/revisions/:revision/rollback", func(c *fiber.Ctx) error {
+		return RollbackLink(c, db, queries, ctx, rdb, "", nil, policy)
+	})
+	app.Post("/api/v1/links/shorten", func(c *fiber.Ctx) error {
+		return ShortenLink(c, db, queries, ctx, "", nil, nil, policy)
+	})
+	return app
+}
+
+// testRequest sends a request to app, a non empty userID signs it with a token of that user
+func testRequest(t *testing.T, app *fiber.App, method, target, body string, userID uuid.UUID) (int, string, map[string]string) {
+	t.Helper()
+
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	if userID != uuid.Nil {
+		token, err := GenerateToken(database.User{ID: userID, Username: "tester"}, testJWTSecret)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set(fiber.HeaderAuthorization, "Bearer "+token)
+	}
+
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	headers := map[string]string{
+		fiber.HeaderLocation:     resp.Header.Get(fiber.HeaderLocation),
+		fiber.HeaderCacheControl: resp.Header.Get(fiber.HeaderCacheControl),
+	}
+	return resp.StatusCode, string(data), headers
+}
+
+func TestGetLinkRedirectStatus(t *testing.T) {
+	tests := []struct {
+		name         string
+		redirectType sql.NullInt32
+		defaultType  int
+		want         int
+	}{
+		{"server default", sql.NullInt32{}, fiber.StatusFound, fiber.StatusFound},
+		{"configured default", sql.NullInt32{}, fiber.StatusTemporaryRedirect, fiber.StatusTemporaryRedirect},
+		{"invalid default", sql.NullInt32{}, fiber.StatusOK, fiber.StatusMovedPermanently},
+		{"link type", sql.NullInt32{Int32: fiber.StatusPermanentRedirect, Valid: true}, fiber.StatusFound, fiber.StatusPermanentRedirect},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, db, queries := newFakeStore(t)
+			store.addLink(database.Shortly{ShortLink: "docs", LongLink: "https://example.com/docs", RedirectType: tt.redirectType, Enabled: true})
+			app := newLinkTestApp(t, db, queries, nil, redirectTestConfig{defaultType: tt.defaultType})
+
+			status, body, headers := testRequest(t, app, fiber.MethodGet, "/docs", "", uuid.Nil)
+			if status != tt.want {
+				t.Fatalf("status = %v, want %v: %v", status, tt.want, body)
+			}
+			if location := headers[fiber.HeaderLocation]; location != "https://example.com/docs" {
+				t.Errorf("Location = %q, want the destination", location)
+			}
+		})
+	}
+}
+
+func TestGetLinkHiddenLinks(t *testing.T) {
+	deletedAt := sql.NullTime{Time: time.Now().Add(-time.Minute), Valid: true}
+	tests := []struct {
+		name string
+		link database.Shortly
+	}{
+		{"disabled", database.Shortly{ShortLink: "docs", LongLink: "https://example.com", Enabled: false}},
+		{"trashed", database.Shortly{ShortLink: "docs", LongLink: "https://example.com", Enabled: true, DeletedAt: deletedAt}},
+		{"trashed and disabled", database.Shortly{ShortLink: "docs", LongLink: "https://example.com", DeletedAt: deletedAt}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, db, queries := newFakeStore(t)
+			store.addLink(tt.link)
+			app := newLinkTestApp(t, db, queries, nil, redirectTestConfig{})
+
+			status, body, headers := testRequest(t, app, fiber.MethodGet, "/docs", "", uuid.Nil)
+			if status != fiber.StatusNotFound {
+				t.Fatalf("status = %v, want %v: %v", status, fiber.StatusNotFound, body)
+			}
+			if !strings.Contains(body, "short url not found") {
+				t.Errorf("body = %v, want short url not found", body)
+			}
+			if location := headers[fiber.HeaderLocation]; location != "" {
+				t.Errorf("Location = %q, want none", location)
+			}
+		})
+	}
+}
+
+func TestGetLinkDisabledURL(t *testing.T) {
+	store, db, queries := newFakeStore(t)
+	store.addLink(database.Shortly{ShortLink: "docs", LongLink: "https://example.com", Enabled: false})
+	app := newLinkTestApp(t, db, queries, nil, redirectTestConfig{disabledURL: "https://example.com/paused"})
+
+	status, _, headers := testRequest(t, app, fiber.MethodGet, "/docs", "", uuid.Nil)
+	if status != fiber.StatusFound || headers[fiber.HeaderLocation] != "https://example.com/paused" {
+		t.Errorf("got %v to %q, want %v to the disabled link page", status, headers[fiber.HeaderLocation], fiber.StatusFound)
+	}
+	if cacheControl := headers[fiber.HeaderCacheControl]; cacheControl != "no-store" {
+		t.Errorf("Cache-Control = %q, want no-store", cacheControl)
+	}
+}
+
+func TestGetLinkNotLive(t *testing.T) {
+	activateAt := sql.NullTime{Time: time.Now().Add(time.Hour), Valid: true}
+
+	t.Run("json", func(t *testing.T) {
+		store, db, queries := newFakeStore(t)
+		link := store.addLink(database.Shortly{ShortLink: "launch", LongLink: "https://example.com", Enabled: true, ActivateAt: activateAt})
+		app := newLinkTestApp(t, db, queries, nil, redirectTestConfig{})
+
+		status, body, headers := testRequest(t, app, fiber.MethodGet, "/launch", "", uuid.Nil)
+		if status != fiber.StatusNotFound || !strings.Contains(body, "short url is not live yet") {
+			t.Errorf("got %v %v, want %v not live yet", status, body, fiber.StatusNotFound)
+		}
+		if cacheControl := headers[fiber.HeaderCacheControl]; cacheControl != "no-store" {
+			t.Errorf("Cache-Control = %q, want no-store", cacheControl)
+		}
+		if clicks := store.link(link.ID).ClickCount; clicks != 0 {
+			t.Errorf("click count = %v, a link that is not live must not count clicks", clicks)
+		}
+	})
+
+	t.Run("not live url", func(t *testing.T) {
+		store, db, queries := newFakeStore(t)
+		store.addLink(database.Shortly{ShortLink: "launch", LongLink: "https://example.com", Enabled: true, ActivateAt: activateAt})
+		app := newLinkTestApp(t, db, queries, nil, redirectTestConfig{notLiveURL: "https://example.com/soon"})
+
+		status, _, headers := testRequest(t, app, fiber.MethodGet, "/launch", "", uuid.Nil)
+		if status != fiber.StatusFound || headers[fiber.HeaderLocation] != "https://example.com/soon" {
+			t.Errorf("got %v to %q, want %v to the not live page", status, headers[fiber.HeaderLocation], fiber.StatusFound)
+		}
+	})
+
+	t.Run("live", func(t *testing.T) {
+		store, db, queries := newFakeStore(t)
+		store.addLink(database.Shortly{ShortLink: "launch", LongLink: "https://example.com", Enabled: true,
+			ActivateAt: sql.NullTime{Time: time.Now().Add(-time.Hour), Valid: true}})
+		app := newLinkTestApp(t, db, queries, nil, redirectTestConfig{})
+
+		if status, body, _ := testRequest(t, app, fiber.MethodGet, "/launch", "", uuid.Nil); status != fiber.StatusFound {
+			t.Errorf("status = %v, want %v: %v", status, fiber.StatusFound, body)
+		}
+	})
+}
+
+func TestGetLinkClampsCacheTTL(t *testing.T) {
+	store, db, queries := newFakeStore(t)
+	cache, rdb := newFakeCache(t)
+	store.addLink(database.Shortly{ShortLink: "sale", LongLink: "https://example.com", Enabled: true,
+		ExpiresAt: sql.NullTime{Time: time.Now().Add(time.Minute), Valid: true}})
+	store.addLink(database.Shortly{ShortLink: "docs", LongLink: "https://example.com/docs", Enabled: true})
+	app := newLinkTestApp(t, db, queries, rdb, redirectTestConfig{})
+
+	for _, link := range []string{"sale", "docs"} {
+		if status, body, _ := testRequest(t, app, fiber.MethodGet, "/"+link, "", uuid.Nil); status != fiber.StatusFound {
+			t.Fatalf("GET /%v status = %v: %v", link, status, body)
+		}
+	}
+
+	if ttl := cache.ttl("sale"); ttl <= 0 || ttl > time.Minute {
+		t.Errorf("cache ttl of an expiring link = %v, want at most its minute to expiry", ttl)
+	}
+	if ttl := cache.ttl("docs"); ttl != time.Hour {
+		t.Errorf("cache ttl = %v, want the configured %v", ttl, time.Hour)
+	}
+}
+
+func TestEditLinkInvalidatesCache(t *testing.T) {
+	store, db, queries := newFakeStore(t)
+	cache, rdb := newFakeCache(t)
+	userID := uuid.New()
+	store.addLink(database.Shortly{UserID: userID, ShortLink: "docs", LongLink: "https://example.com/docs", Enabled: true})
+	app := newLinkTestApp(t, db, queries, rdb, redirectTestConfig{})
+
+	if status, _, _ := testRequest(t, app, fiber.MethodGet, "/docs", "", uuid.Nil); status != fiber.StatusFound {
+		t.Fatalf("status = %v, want %v", status, fiber.StatusFound)
+	}
+	if _, ok := cache.get("docs"); !ok {
+		t.Fatal("the redirect was not cached")
+	}
+
+	if status, body, _ := testRequest(t, app, fiber.MethodPatch, "/api/v1/links/docs", `{"redirect_type": 308}`, userID); status != fiber.StatusOK {
+		t.Fatalf("edit status = %v: %v", status, body)
+	}
+	if _, ok := cache.get("docs"); ok {
+		t.Error("the edit left the link in the cache")
+	}
+	if status, _, _ := testRequest(t, app, fiber.MethodGet, "/docs", "", uuid.Nil); status != fiber.StatusPermanentRedirect {
+		t.Errorf("status after the edit = %v, want %v", status, fiber.StatusPermanentRedirect)
+	}
+
+	if status, body, _ := testRequest(t, app, fiber.MethodPatch, "/api/v1/links/docs", `{"enabled": false}`, userID); status != fiber.StatusOK {
+		t.Fatalf("disable status = %v: %v", status, body)
+	}
+	if status, _, _ := testRequest(t, app, fiber.MethodGet, "/docs", "", uuid.Nil); status != fiber.StatusNotFound {
+		t.Errorf("status after disabling = %v, want %v", status, fiber.StatusNotFound)
+	}
+}
+
+func TestRollbackLinkInvalidatesCache(t *testing.T) {
+	store, db, queries := newFakeStore(t)
+	cache, rdb := newFakeCache(t)
+	userID := uuid.New()
+	store.addLink(database.Shortly{UserID: userID, ShortLink: "docs", LongLink: "https://example.com/docs", Enabled: true})
+	app := newLinkTestApp(t, db, queries, rdb, redirectTestConfig{})
+
+	if status, body, _ := testRequest(t, app, fiber.MethodPatch, "/api/v1/links/docs", `{"alias": "guide", "redirect_type": 301}`, userID); status != fiber.StatusOK {
+		t.Fatalf("edit status = %v: %v", status, body)
+	}
+	if status, _, _ := testRequest(t, app, fiber.MethodGet, "/guide", "", uuid.Nil); status != fiber.StatusMovedPermanently {
+		t.Fatalf("status after the edit = %v, want %v", status, fiber.StatusMovedPermanently)
+	}
+	if _, ok := cache.get("guide"); !ok {
+		t.Fatal("the redirect was not cached")
+	}
+
+	if status, body, _ := testRequest(t, app, fiber.MethodPost, "/api/v1/links/guide/revisions/1/rollback", "", userID); status != fiber.StatusOK {
+		t.Fatalf("rollback status = %v: %v", status, body)
+	}
+	if _, ok := cache.get("guide"); ok {
+		t.Error("the rollback left the renamed link in the cache")
+	}
+	if status, _, _ := testRequest(t, app, fiber.MethodGet, "/guide", "", uuid.Nil); status != fiber.StatusNotFound {
+		t.Errorf("status of the rolled back alias = %v, want %v", status, fiber.StatusNotFound)
+	}
+	status, _, headers := testRequest(t, app, fiber.MethodGet, "/docs", "", uuid.Nil)
+	if status != fiber.StatusFound || headers[fiber.HeaderLocation] != "https://example.com/docs" {
+		t.Errorf("got %v to %q after the rollback, want %v to the original destination", status, headers[fiber.HeaderLocation], fiber.StatusFound)
+	}
+}
+
+func TestShortenLinkValidation(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{"redirect type", `{"url": "https://example.com", "redirect_type": 303}`, "redirect_type must be one of 301, 302, 307 or 308"},
+		{"activate_at in the past", `{"url": "https://example.com", "activate_at": "2020-01-01T00:00:00Z"}`, "activate_at must be in the future"},
+		{"activate_at not a time", `{"url": "https://example.com", "activate_at": "soon"}`, "activate_at must be an RFC3339 time or a duration"},
+		{"activate_at after expires_at", `{"url": "https://example.com", "activate_at": "2h", "expires_at": "1h"}`, "activate_at must be before expires_at"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, db, queries := newFakeStore(t)
+			app := newLinkTestApp(t, db, queries, nil, redirectTestConfig{})
+
+			status, body, _ := testRequest(t, app, fiber.MethodPost, "/api/v1/links/shorten", tt.body, uuid.New())
+			if status != fiber.StatusBadRequest || !strings.Contains(body, tt.want) {
+				t.Errorf("got %v %v, want %v %v", status, body, fiber.StatusBadRequest, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,360 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"github.com/tin3ga/shortly/internal/database"
+)
+
+const (
+	maxTagsPerLink  = 20
+	maxTagLength    = 50
+	maxFolderLength = 100
+	maxNotesLength  = 1000
+)
+
+// Tag model info
+//
+//	@Description	Tag Model
+//	@Description	Name
+type TagModel struct {
+	Name string `json:"name"`
+}
+
+// Merge Tag model info
+//
+//	@Description	Merge Tag Model
+//	@Description	Into
+type MergeTagModel struct {
+	// Tag that receives the links, it is created if it does not exist
+	Into string `json:"into"`
+}
+
+// TaggedLink is a link together with the names of its tags
+type TaggedLink struct {
+	database.Shortly
+	Tags []string `json:"tags"`
+}
+
+// normalizeTag trims and lower cases a tag name
+func normalizeTag(name string) (string, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		return "", fmt.Errorf("tag names cannot be empty")
+	}
+	if utf8.RuneCountInString(name) > maxTagLength {
+		return "", fmt.Errorf("tag names must be at most %d characters", maxTagLength)
+	}
+	return name, nil
+}
+
+// normalizeTags normalizes every tag and removes duplicates, keeping the original order
+func normalizeTags(names []string) ([]string, error) {
+	seen := make(map[string]bool)
+	tags := make([]string, 0, len(names))
+	for _, name := range names {
+		tag, err := normalizeTag(name)
+		if err != nil {
+			return nil, err
+		}
+		if !seen[tag] {
+			seen[tag] = true
+			tags = append(tags, tag)
+		}
+	}
+	if len(tags) > maxTagsPerLink {
+		return nil, fmt.Errorf("a link can have at most %d tags", maxTagsPerLink)
+	}
+	return tags, nil
+}
+
+// normalizeFolder trims a folder name, an empty name means the link is not in a folder
+func normalizeFolder(name string) (sql.NullString, error) {
+	name = strings.TrimSpace(name)
+	if utf8.RuneCountInString(name) > maxFolderLength {
+		return sql.NullString{}, fmt.Errorf("folder names must be at most %d characters", maxFolderLength)
+	}
+	return sql.NullString{String: name, Valid: name != ""}, nil
+}
+
+// setLinkTags replaces the tags of a link, tags the user does not have yet are created
+func setLinkTags(ctx context.Context, queries *database.Queries, userID uuid.UUID, linkID uuid.UUID, tags []string) error {
+	if err := queries.ClearLinkTags(ctx, linkID); err != nil {
+		return err
+	}
+
+	for _, name := range tags {
+		tag, err := queries.UpsertTag(ctx, database.UpsertTagParams{ID: uuid.New(), UserID: userID, Name: name})
+		if err != nil {
+			return err
+		}
+		if err := queries.AddLinkTag(ctx, database.AddLinkTagParams{LinkID: linkID, TagID: tag.ID}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// withTags loads the tags of all links with a single query
+func withTags(ctx context.Context, queries *database.Queries, links []database.Shortly) ([]TaggedLink, error) {
+	ids := make([]uuid.UUID, 0, len(links))
+	for _, link := range links {
+		ids = append(ids, link.ID)
+	}
+
+	rows, err := queries.GetTagsForLinks(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	tags := make(map[uuid.UUID][]string)
+	for _, row := range rows {
+		tags[row.LinkID] = append(tags[row.LinkID], row.Name)
+	}
+
+	tagged := make([]TaggedLink, 0, len(links))
+	for _, link := range links {
+		linkTags := tags[link.ID]
+		if linkTags == nil {
+			linkTags = []string{}
+		}
+		tagged = append(tagged, TaggedLink{Shortly: link, Tags: linkTags})
+	}
+
+	return tagged, nil
+}
+
+// normalizeNotes trims the notes of a link, empty notes are removed
+func normalizeNotes(notes string) (sql.NullString, error) {
+	notes = strings.TrimSpace(notes)
+	if utf8.RuneCountInString(notes) > maxNotesLength {
+		return sql.NullString{}, fmt.Errorf("notes must be at most %d characters", maxNotesLength)
+	}
+	return sql.NullString{String: notes, Valid: notes != ""}, nil
+}
+
+// organizeLink updates the folder, notes and tags of a link, fields left nil in input are unchanged
+func organizeLink(ctx context.Context, db *sql.DB, queries *database.Queries, data database.Shortly, input *EditLinkModel) (database.Shortly, error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return database.Shortly{}, err
+	}
+	defer tx.Rollback()
+
+	qtx := queries.WithTx(tx)
+
+	if input.Folder != nil {
+		name, err := normalizeFolder(*input.Folder)
+		if err != nil {
+			return database.Shortly{}, fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+		data, err = qtx.SetLinkFolder(ctx, database.SetLinkFolderParams{ID: data.ID, Folder: name})
+		if err != nil {
+			return database.Shortly{}, err
+		}
+	}
+
+	if input.Notes != nil {
+		notes, err := normalizeNotes(*input.Notes)
+		if err != nil {
+			return database.Shortly{}, fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+		data, err = qtx.SetLinkNotes(ctx, database.SetLinkNotesParams{ID: data.ID, Notes: notes})
+		if err != nil {
+			return database.Shortly{}, err
+		}
+	}
+
+	if input.Tags != nil {
+		names, err := normalizeTags(input.Tags)
+		if err != nil {
+			return database.Shortly{}, fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+		if err := setLinkTags(ctx, qtx, data.UserID, data.ID, names); err != nil {
+			return database.Shortly{}, err
+		}
+	}
+
+	return data, tx.Commit()
+}
+
+// tagParam reads the url encoded tag name from the path
+func tagParam(c *fiber.Ctx) (string, error) {
+	name, err := url.PathUnescape(c.Params("name"))
+	if err != nil {
+		return "", err
+	}
+	return normalizeTag(name)
+}
+
+// getOwnedTag fetches a tag of the authenticated user by name
+func getOwnedTag(c *fiber.Ctx, queries *database.Queries, ctx context.Context) (database.Tag, error) {
+	userID, err := getUserID(c)
+	if err != nil {
+		log.Printf("Error parsing UserID: %v", err)
+		return database.Tag{}, fiber.NewError(fiber.StatusBadRequest, "Invalid UserID format")
+	}
+
+	name, err := tagParam(c)
+	if err != nil {
+		return database.Tag{}, fiber.NewError(fiber.StatusBadRequest, "Invalid tag name")
+	}
+
+	tag, err := queries.GetTagByName(ctx, database.GetTagByNameParams{UserID: userID, Name: name})
+	if err != nil {
+		return database.Tag{}, fiber.NewError(fiber.StatusNotFound, "tag not found")
+	}
+
+	return tag, nil
+}
+
+// getTags Fetch the tags of a user
+//
+//	@Summary		Fetch the tags of a user
+//	@Description	Returns all tags with the number of links using them
+//	@Tags			protected
+//	@Security		BearerAuth
+//	@Produce		json
+//	@Success		200
+//	@Failure		400
+//	@Failure		404
+//	@Router			/api/v1/tags/ [get]
+func GetTags(c *fiber.Ctx, queries *database.Queries, ctx context.Context) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		log.Printf("Error parsing UserID: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"success": false, "message": "Invalid UserID format"})
+	}
+
+	tags, err := queries.GetUserTags(ctx, userID)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Cannot fetch tags"})
+	}
+
+	return c.JSON(tags)
+}
+
+// renameTag Rename a tag
+//
+//	@Summary		Rename a tag
+//	@Description	Renames the tag on all links, use merge when the new name is already a tag
+//	@Param			name	path	string		true	"Tag name"
+//	@Param			tag		body	TagModel	true	"New name"
+//	@Tags			protected
+//	@Security		BearerAuth
+//	@Produce		json
+//	@Success		200
+//	@Failure		400
+//	@Failure		404
+//	@Failure		409
+//	@Failure		500
+//	@Router			/api/v1/tags/{name} [patch]
+func RenameTag(c *fiber.Ctx, queries *database.Queries, ctx context.Context) error {
+	input := new(TagModel)
+
+	if err := c.BodyParser(input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Cannot parse JSON"})
+	}
+
+	name, err := normalizeTag(input.Name)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error(), "name": input.Name})
+	}
+
+	tag, err := getOwnedTag(c, queries, ctx)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	renamed, err := queries.RenameTag(ctx, database.RenameTagParams{ID: tag.ID, Name: name})
+	if err != nil {
+		log.Print(err)
+		if strings.Contains(err.Error(), "unique_user_tag") {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "Tag already exists, merge the tags instead", "name": name})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Cannot rename tag"})
+	}
+
+	log.Printf("Renamed tag %v to %v", tag.Name, renamed.Name)
+	return c.JSON(fiber.Map{"Success": "Tag renamed", "Data": renamed})
+}
+
+// mergeTags Merge a tag into another tag
+//
+//	@Summary		Merge a tag into another tag
+//	@Description	Moves all links of the tag to the target tag and removes the merged tag
+//	@Param			name	path	string			true	"Tag to merge"
+//	@Param			tag		body	MergeTagModel	true	"Target tag"
+//	@Tags			protected
+//	@Security		BearerAuth
+//	@Produce		json
+//	@Success		200
+//	@Failure		400
+//	@Failure		404
+//	@Failure		500
+//	@Router			/api/v1/tags/{name}/merge [post]
+func MergeTags(c *fiber.Ctx, db *sql.DB, queries *database.Queries, ctx context.Context) error {
+	input := new(MergeTagModel)
+
+	if err := c.BodyParser(input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Cannot parse JSON"})
+	}
+
+	into, err := normalizeTag(input.Into)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error(), "into": input.Into})
+	}
+
+	source, err := getOwnedTag(c, queries, ctx)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+	if source.Name == into {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Cannot merge a tag into itself"})
+	}
+
+	target, err := mergeTags(ctx, db, queries, source, into)
+	if err != nil {
+		log.Print(err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Cannot merge tags"})
+	}
+
+	log.Printf("Merged tag %v into %v", source.Name, target.Name)
+	return c.JSON(fiber.Map{"Success": "Tags merged", "Data": target})
+}
+
+func mergeTags(ctx context.Context, db *sql.DB, queries *database.Queries, source database.Tag, into string) (database.Tag, error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return database.Tag{}, err
+	}
+	defer tx.Rollback()
+
+	qtx := queries.WithTx(tx)
+
+	target, err := qtx.UpsertTag(ctx, database.UpsertTagParams{ID: uuid.New(), UserID: source.UserID, Name: into})
+	if err != nil {
+		return database.Tag{}, err
+	}
+
+	if err := qtx.MergeTagLinks(ctx, database.MergeTagLinksParams{TargetID: target.ID, SourceID: source.ID}); err != nil {
+		return database.Tag{}, err
+	}
+
+	// removing the tag also removes its remaining link_tags rows
+	if err := qtx.DeleteTag(ctx, source.ID); err != nil {
+		return database.Tag{}, err
+	}
+
+	return target, tx.Commit()
+}
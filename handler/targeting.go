@@ -0,0 +1,217 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"slices"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/tin3ga/shortly/geoip"
+	"github.com/tin3ga/shortly/internal/database"
+	"github.com/tin3ga/shortly/targeting"
+)
+
+// Targeting model info
+//
+//	@Description	Ordered targeting rules, the first rule matching the visitor's User-Agent wins
+//	@Description	Visitors matching no rule are sent to the link's url
+type TargetingModel struct {
+	Rules []targeting.Rule `json:"rules"`
+}
+
+// targetedDestination returns the destination of the first rule matching the visitor, false when none does.
+// Device rules are checked before geo rules.
+func targetedDestination(c *fiber.Ctx, geo *geoip.Reader, data database.Shortly) (string, bool) {
+	var rules []targeting.Rule
+	if err := decodeRules(data.TargetingRules, &rules); err != nil {
+		log.Print(err)
+	}
+	if len(rules) > 0 {
+		// the same short url redirects differently per device, shared caches must keep them apart
+		c.Vary(fiber.HeaderUserAgent)
+
+		if destination, ok := targeting.Match(rules, c.Get(fiber.HeaderUserAgent)); ok {
+			return destination, true
+		}
+	}
+
+	var geoRules []targeting.GeoRule
+	if err := decodeRules(data.GeoRules, &geoRules); err != nil {
+		log.Print(err)
+	}
+	if len(geoRules) > 0 {
+		if destination, ok := targeting.MatchCountry(geoRules, geo.Country(ClientIP(c))); ok {
+			return destination, true
+		}
+	}
+
+	return "", false
+}
+
+// decodeRules reads rules stored as JSON, links without rules leave v untouched
+func decodeRules(raw json.RawMessage, v any) error {
+	if len(raw) == 0 {
+		return nil
+	}
+	return json.Unmarshal(raw, v)
+}
+
+// hasRules reports whether a JSON list of rules stored on a link has any entries
+func hasRules(raw json.RawMessage) bool {
+	return len(raw) > 0 && string(raw) != "[]"
+}
+
+// isBlockedCountry reports whether the visitor is in a country the link is blocked in
+func isBlockedCountry(c *fiber.Ctx, geo *geoip.Reader, data database.Shortly) bool {
+	if len(data.BlockedCountries) == 0 {
+		return false
+	}
+	return slices.Contains(data.BlockedCountries, geo.Country(ClientIP(c)))
+}
+
+// setLinkTargeting Replace the targeting rules of a Short URL
+//
+//	@Summary		Replace the targeting rules of a Short URL
+//	@Description	Rules match on os (ios, android, windows, macos, linux, chromeos, other), device (mobile, tablet, desktop) and browser
+//	@Description	Every destination is scanned like the link's url, an empty list removes all rules
+//	@Param			alias	path	string			true	"Short URL"
+//	@Param			rules	body	TargetingModel	true	"Targeting rules"
+//	@Tags			protected
+//	@Security		BearerAuth
+//	@Produce		json
+//	@Success		200
+//	@Failure		400
+//	@Failure		404
+//	@Failure		500
+//	@Router			/api/v1/links/{alias}/targeting [put]
+func SetLinkTargeting(c *fiber.Ctx, queries *database.Queries, ctx context.Context, rdb *redis.Client, apiKey string) error {
+	input := new(TargetingModel)
+
+	if err := c.BodyParser(input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Cannot parse JSON"})
+	}
+
+	rules, err := targeting.Normalize(input.Rules)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	data, err := getOwnedLink(c, queries, ctx, aliasParam(c, "alias"))
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	for _, rule := range rules {
+		if !hasValidScheme(rule.Url) {
+			log.Printf("Invalid URL scheme: %v", rule.Url)
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "URL must start with https://", "url": rule.Url})
+		}
+		if _, err := scanLink(apiKey, rule.Url); err != nil {
+			return errorResponse(c, err)
+		}
+	}
+
+	encoded, err := json.Marshal(rules)
+	if err != nil {
+		log.Print(err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Cannot set targeting rules"})
+	}
+
+	params := database.SetLinkTargetingRulesParams{
+		ID:             data.ID,
+		TargetingRules: encoded,
+	}
+	updated, err := queries.SetLinkTargetingRules(ctx, params)
+	if err != nil {
+		log.Print(err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Cannot set targeting rules"})
+	}
+
+	invalidateCache(ctx, rdb, updated)
+
+	log.Printf("Set %v targeting rules on: %v", len(rules), updated.ShortLink)
+	return c.JSON(fiber.Map{"Success": "Targeting rules updated", "rules": rules})
+}
+
+// Geo targeting model info
+//
+//	@Description	Ordered country rules and countries the link is blocked in
+//	@Description	Visitors from a blocked country get 451, visitors matching no rule are sent to the link's url
+type GeoTargetingModel struct {
+	Rules []targeting.GeoRule `json:"rules"`
+	// ISO 3166-1 alpha-2 country codes
+	Blocked_countries []string `json:"blocked_countries"`
+}
+
+// setLinkGeoTargeting Replace the geo rules and country blocklist of a Short URL
+//
+//	@Summary		Replace the geo rules and country blocklist of a Short URL
+//	@Description	The country of a visitor is looked up in the configured GeoIP database, rules are skipped when none is configured
+//	@Description	Device targeting rules are checked before geo rules, empty lists remove the rules or the blocklist
+//	@Param			alias	path	string				true	"Short URL"
+//	@Param			rules	body	GeoTargetingModel	true	"Geo rules and blocklist"
+//	@Tags			protected
+//	@Security		BearerAuth
+//	@Produce		json
+//	@Success		200
+//	@Failure		400
+//	@Failure		404
+//	@Failure		500
+//	@Router			/api/v1/links/{alias}/geo [put]
+func SetLinkGeoTargeting(c *fiber.Ctx, queries *database.Queries, ctx context.Context, rdb *redis.Client, apiKey string) error {
+	input := new(GeoTargetingModel)
+
+	if err := c.BodyParser(input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Cannot parse JSON"})
+	}
+
+	rules, err := targeting.NormalizeGeo(input.Rules)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	blocked, err := targeting.NormalizeCountries(input.Blocked_countries)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error(), "blocked_countries": input.Blocked_countries})
+	}
+
+	data, err := getOwnedLink(c, queries, ctx, aliasParam(c, "alias"))
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	for _, rule := range rules {
+		if !hasValidScheme(rule.Url) {
+			log.Printf("Invalid URL scheme: %v", rule.Url)
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "URL must start with https://", "url": rule.Url})
+		}
+		if _, err := scanLink(apiKey, rule.Url); err != nil {
+			return errorResponse(c, err)
+		}
+	}
+
+	encoded, err := json.Marshal(rules)
+	if err != nil {
+		log.Print(err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Cannot set geo rules"})
+	}
+
+	params := database.SetLinkGeoRulesParams{
+		ID:               data.ID,
+		GeoRules:         encoded,
+		BlockedCountries: blocked,
+	}
+	updated, err := queries.SetLinkGeoRules(ctx, params)
+	if err != nil {
+		log.Print(err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Cannot set geo rules"})
+	}
+
+	invalidateCache(ctx, rdb, updated)
+
+	log.Printf("Set %v geo rules and %v blocked countries on: %v", len(rules), len(blocked), updated.ShortLink)
+	return c.JSON(fiber.Map{"Success": "Geo rules updated", "rules": rules, "blocked_countries": blocked})
+}
@@ -0,0 +1,377 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"github.com/tin3ga/shortly/alias"
+	"github.com/tin3ga/shortly/internal/database"
+)
+
+var linkRecordHeader = []string{"alias", "destination", "click_count", "created_at", "updated_at", "expires_at", "domain"}
+
+// Link Record model info
+//
+//	@Description	Link Record Model used by export and import
+//	@Description	Alias, Destination, Click_count, Created_at, Updated_at, Expires_at, Domain
+type LinkRecord struct {
+	Alias       string     `json:"alias"`
+	Destination string     `json:"destination"`
+	Click_count int32      `json:"click_count"`
+	Created_at  time.Time  `json:"created_at"`
+	Updated_at  time.Time  `json:"updated_at"`
+	Expires_at  *time.Time `json:"expires_at"`
+	// Custom domain of the link, empty for the default domain
+	Domain string `json:"domain"`
+}
+
+// newLinkRecord returns the export of a link, hosts maps the ids of the user's domains to their host
+func newLinkRecord(data database.Shortly, hosts map[uuid.UUID]string) LinkRecord {
+	record := LinkRecord{
+		Alias:       data.ShortLink,
+		Destination: data.LongLink,
+		Click_count: data.ClickCount,
+		Created_at:  data.CreatedAt,
+		Updated_at:  data.UpdatedAt,
+	}
+	if data.DomainID.Valid {
+		record.Domain = hosts[data.DomainID.UUID]
+	}
+	if data.ExpiresAt.Valid {
+		record.Expires_at = &data.ExpiresAt.Time
+	}
+	return record
+}
+
+func writeLinkRecordsCSV(w io.Writer, records []LinkRecord) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(linkRecordHeader); err != nil {
+		return err
+	}
+
+	for _, record := range records {
+		expiresAt := ""
+		if record.Expires_at != nil {
+			expiresAt = record.Expires_at.Format(time.RFC3339)
+		}
+		row := []string{
+			record.Alias,
+			record.Destination,
+			strconv.Itoa(int(record.Click_count)),
+			record.Created_at.Format(time.RFC3339),
+			record.Updated_at.Format(time.RFC3339),
+			expiresAt,
+			record.Domain,
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// readLinkRecordsCSV reads the alias, destination, expires_at and domain columns, any other column is ignored
+func readLinkRecordsCSV(r io.Reader) ([]LinkRecord, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, err
+	}
+
+	aliasColumn, destinationColumn, expiresColumn, domainColumn := -1, -1, -1, -1
+	for i, name := range header {
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "alias":
+			aliasColumn = i
+		case "destination":
+			destinationColumn = i
+		case "expires_at":
+			expiresColumn = i
+		case "domain":
+			domainColumn = i
+		}
+	}
+	if destinationColumn == -1 {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "CSV header must contain a destination column")
+	}
+
+	var records []LinkRecord
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		var record LinkRecord
+		if aliasColumn != -1 && aliasColumn < len(row) {
+			record.Alias = strings.TrimSpace(row[aliasColumn])
+		}
+		if destinationColumn < len(row) {
+			record.Destination = strings.TrimSpace(row[destinationColumn])
+		}
+		if expiresColumn != -1 && expiresColumn < len(row) && strings.TrimSpace(row[expiresColumn]) != "" {
+			expiresAt, err := time.Parse(time.RFC3339, strings.TrimSpace(row[expiresColumn]))
+			if err != nil {
+				return nil, fiber.NewError(fiber.StatusBadRequest, "expires_at must be an RFC3339 time")
+			}
+			record.Expires_at = &expiresAt
+		}
+		if domainColumn != -1 && domainColumn < len(row) {
+			record.Domain = strings.TrimSpace(row[domainColumn])
+		}
+		records = append(records, record)
+	}
+
+	return records, nil
+}
+
+// dryRunImport validates the rows and reports aliases that are already taken without creating anything
+func dryRunImport(ctx context.Context, queries *database.Queries, policy *alias.Policy, items []BulkLinkItem) ([]BulkLinkResult, error) {
+	results := make([]BulkLinkResult, len(items))
+
+	aliases := make(map[uuid.NullUUID][]string)
+	for i, item := range items {
+		results[i] = newBulkLinkResult(i, item)
+		if results[i].Custom_alias != "" {
+			aliases[item.domainID] = append(aliases[item.domainID], results[i].Custom_alias)
+		}
+	}
+
+	// rows of the import are also checked against each other, by key when the policy ignores case
+	taken := make(map[string]bool)
+	for domainID, domainAliases := range aliases {
+		used, err := usedAliases(ctx, queries, policy, domainID, domainAliases)
+		if err != nil {
+			return nil, err
+		}
+		for custom, inUse := range used {
+			if inUse {
+				taken[importAliasKey(policy, domainID, custom)] = true
+			}
+		}
+	}
+
+	for i := range results {
+		result := &results[i]
+		aliasErr := validateAlias(policy, result.Custom_alias)
+		key := importAliasKey(policy, result.domainID, result.Custom_alias)
+		switch {
+		case !result.checkDomain():
+		case result.expiresAt.Valid && !result.expiresAt.Time.After(time.Now()):
+			result.Status = BulkStatusExpired
+			result.Error = "Link has already expired"
+		case !hasValidScheme(result.Url):
+			result.Status = BulkStatusInvalidScheme
+			result.Error = "URL must start with https://"
+		case aliasErr != nil:
+			result.setInvalidAlias(aliasErr)
+		case result.Custom_alias != "" && taken[key]:
+			result.Status = BulkStatusDuplicateAlias
+			result.Error = "Duplicate short link, create a new alias"
+		default:
+			result.Status = BulkStatusValid
+			if result.Custom_alias != "" {
+				taken[key] = true
+			}
+		}
+	}
+
+	return results, nil
+}
+
+// importAliasKey identifies an alias of an import, aliases only conflict on the same domain
+func importAliasKey(policy *alias.Policy, domainID uuid.NullUUID, custom string) string {
+	return cacheKey(domainID, policy.MatchKey(custom))
+}
+
+// exportLinks Export all links of a user
+//
+//	@Summary		Export all user links
+//	@Description	Returns alias, destination, click count, timestamps and custom domain as CSV or JSON
+//	@Param			format	query	string	false	"csv or json (default json)"
+//	@Tags			protected
+//	@Security		BearerAuth
+//	@Produce		json
+//	@Produce		text/csv
+//	@Success		200
+//	@Failure		400
+//	@Failure		404
+//	@Router			/api/v1/links/export [get]
+func ExportLinks(c *fiber.Ctx, queries *database.Queries, ctx context.Context) error {
+	format := c.Query("format", "json")
+	if format != "csv" && format != "json" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "format must be csv or json"})
+	}
+
+	userID, err := getUserID(c)
+	if err != nil {
+		log.Printf("Error parsing UserID: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"success": false, "message": "Invalid UserID format"})
+	}
+
+	data, err := queries.GetUserLinks(ctx, userID)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Cannot fetch links"})
+	}
+
+	domains, err := queries.GetUserDomains(ctx, userID)
+	if err != nil {
+		log.Print(err)
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Cannot fetch links"})
+	}
+	hosts := make(map[uuid.UUID]string, len(domains))
+	for _, domain := range domains {
+		hosts[domain.ID] = domain.Host
+	}
+
+	records := make([]LinkRecord, 0, len(data))
+	for _, link := range data {
+		records = append(records, newLinkRecord(link, hosts))
+	}
+
+	log.Printf("Exporting %v links as %v", len(records), format)
+
+	c.Attachment("shortly-links." + format)
+	if format == "json" {
+		return c.JSON(records)
+	}
+
+	var buf bytes.Buffer
+	if err := writeLinkRecordsCSV(&buf, records); err != nil {
+		log.Print(err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Cannot export links"})
+	}
+	c.Set(fiber.HeaderContentType, "text/csv; charset=utf-8")
+	return c.Send(buf.Bytes())
+}
+
+// importLinks Import links from CSV or JSON
+//
+//	@Summary		Import links from CSV or JSON
+//	@Description	Accepts the export format (text/csv or application/json), alias, destination, expires_at and domain are imported
+//	@Description	Rows that have already expired or whose domain is not registered to the user or not verified are skipped
+//	@Description	With dry_run=true rows are validated and checked for alias conflicts without creating links
+//	@Param			dry_run	query	bool			false	"Validate without creating links"
+//	@Param			links	body	[]LinkRecord	true	"Links to import"
+//	@Tags			protected
+//	@Security		BearerAuth
+//	@Accept			json
+//	@Accept			text/csv
+//	@Produce		json
+//	@Success		200
+//	@Failure		400
+//	@Failure		413
+//	@Failure		500
+//	@Router			/api/v1/links/import [post]
+func ImportLinks(c *fiber.Ctx, db *sql.DB, queries *database.Queries, ctx context.Context, apiKey string, workers int, maxItems int, aliases *alias.Generators, policy *alias.Policy) error {
+	var records []LinkRecord
+	var err error
+
+	if strings.HasPrefix(c.Get(fiber.HeaderContentType), "text/csv") {
+		records, err = readLinkRecordsCSV(bytes.NewReader(c.Body()))
+	} else {
+		err = json.Unmarshal(c.Body(), &records)
+	}
+	if err != nil {
+		log.Print(err)
+		var fiberErr *fiber.Error
+		if errors.As(err, &fiberErr) {
+			return errorResponse(c, err)
+		}
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Cannot parse import file"})
+	}
+
+	if workers <= 0 {
+		workers = defaultBulkWorkers
+	}
+	if maxItems <= 0 {
+		maxItems = defaultBulkMaxItems
+	}
+
+	if len(records) == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "No links provided"})
+	}
+	if len(records) > maxItems {
+		return c.Status(fiber.StatusRequestEntityTooLarge).JSON(fiber.Map{"error": "Too many links in one request", "max_items": maxItems})
+	}
+
+	userID, err := getUserID(c)
+	if err != nil {
+		log.Printf("Error parsing UserID: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"success": false, "message": "Invalid UserID format"})
+	}
+
+	domains, err := queries.GetUserDomains(ctx, userID)
+	if err != nil {
+		log.Print(err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Cannot import links"})
+	}
+	domainIDs := make(map[string]uuid.UUID, len(domains))
+	unverified := make(map[string]bool)
+	for _, domain := range domains {
+		if domain.VerifiedAt.Valid {
+			domainIDs[domain.Host] = domain.ID
+		} else {
+			unverified[domain.Host] = true
+		}
+	}
+
+	items := make([]BulkLinkItem, len(records))
+	for i, record := range records {
+		items[i] = BulkLinkItem{Url: record.Destination, Custom_alias: record.Alias, domain: normalizeHost(record.Domain)}
+		if record.Expires_at != nil {
+			items[i].expiresAt = sql.NullTime{Time: *record.Expires_at, Valid: true}
+		}
+		if id, ok := domainIDs[items[i].domain]; ok {
+			items[i].domainID = uuid.NullUUID{UUID: id, Valid: true}
+		}
+		items[i].unverified = unverified[items[i].domain]
+	}
+
+	if c.QueryBool("dry_run") {
+		results, err := dryRunImport(ctx, queries, policy, items)
+		if err != nil {
+			log.Print(err)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Cannot validate import"})
+		}
+		return c.JSON(fiber.Map{"dry_run": true, "results": results})
+	}
+
+	generator, err := aliases.Get("")
+	if err != nil {
+		log.Print(err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Cannot import links"})
+	}
+
+	results := scanBulkItems(items, policy, apiKey, workers)
+
+	if err := insertBulkItems(ctx, db, queries, generator, policy, userID, results); err != nil {
+		log.Print(err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Cannot import links"})
+	}
+
+	created := countCreated(results)
+	log.Printf("Imported %v of %v links", created, len(results))
+
+	return c.JSON(fiber.Map{"dry_run": false, "created": created, "failed": len(results) - created, "results": results})
+}
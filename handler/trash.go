@@ -0,0 +1,142 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/tin3ga/shortly/alias"
+	"github.com/tin3ga/shortly/internal/database"
+)
+
+// disabledLink answers visits to a paused link, visitors are redirected to disabledURL when it is set
+func disabledLink(c *fiber.Ctx, disabledURL string) error {
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	if disabledURL != "" {
+		return c.Redirect(disabledURL, fiber.StatusFound)
+	}
+	return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "short url not found"})
+}
+
+// getOwnedTrashedLink fetches a deleted link by its alias and checks that it belongs to the authenticated user.
+// Aliases are matched like findLink matches live links.
+func getOwnedTrashedLink(c *fiber.Ctx, queries *database.Queries, ctx context.Context, policy *alias.Policy, link string) (database.Shortly, error) {
+	userID, err := getUserID(c)
+	if err != nil {
+		log.Printf("Error parsing UserID: %v", err)
+		return database.Shortly{}, fiber.NewError(fiber.StatusBadRequest, "Invalid UserID format")
+	}
+
+	domainID, err := ownedDomainID(c, queries, ctx, c.Query("domain"))
+	if err != nil {
+		return database.Shortly{}, err
+	}
+
+	data, err := queries.GetTrashedLink(ctx, database.GetTrashedLinkParams{ShortLink: alias.Normalize(link), DomainID: domainID})
+	if errors.Is(err, sql.ErrNoRows) && policy.CaseInsensitive {
+		data, err = queries.GetTrashedLinkByAliasKey(ctx, database.GetTrashedLinkByAliasKeyParams{AliasKey: alias.Key(link), DomainID: domainID})
+	}
+	if err != nil || data.UserID != userID {
+		return database.Shortly{}, fiber.NewError(fiber.StatusNotFound, "short url not found in trash")
+	}
+
+	return data, nil
+}
+
+// getTrash List the deleted links of a user
+//
+//	@Summary		List the deleted links of a user
+//	@Description	Deleted links stop redirecting and are removed for good once the trash retention has passed
+//	@Tags			protected
+//	@Security		BearerAuth
+//	@Produce		json
+//	@Success		200
+//	@Failure		400
+//	@Failure		500
+//	@Router			/api/v1/links/trash [get]
+func GetTrash(c *fiber.Ctx, queries *database.Queries, ctx context.Context) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		log.Printf("Error parsing UserID: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"success": false, "message": "Invalid UserID format"})
+	}
+
+	links, err := queries.GetTrashedLinks(ctx, userID)
+	if err != nil {
+		log.Print(err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Cannot fetch trash"})
+	}
+
+	data, err := withTags(ctx, queries, links)
+	if err != nil {
+		log.Print(err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Cannot fetch trash"})
+	}
+
+	return c.JSON(data)
+}
+
+// restoreLink Restore a deleted Short URL
+//
+//	@Summary		Restore a deleted Short URL
+//	@Description	The link redirects again with its clicks, tags and revisions intact
+//	@Param			alias	path	string	true	"Short URL"
+//	@Param			domain	query	string	false	"Custom domain of the link"
+//	@Tags			protected
+//	@Security		BearerAuth
+//	@Produce		json
+//	@Success		200
+//	@Failure		400
+//	@Failure		404
+//	@Failure		500
+//	@Router			/api/v1/links/trash/{alias}/restore [post]
+func RestoreLink(c *fiber.Ctx, queries *database.Queries, ctx context.Context, rdb *redis.Client, policy *alias.Policy) error {
+	data, err := getOwnedTrashedLink(c, queries, ctx, policy, aliasParam(c, "alias"))
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	restored, err := queries.RestoreLink(ctx, data.ID)
+	if err != nil {
+		log.Print(err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Cannot restore short link"})
+	}
+
+	invalidateCache(ctx, rdb, restored)
+
+	log.Println("Restored a shortened link: ", restored.ShortLink)
+	return c.JSON(fiber.Map{"Success": "Shortened link restored", "Data": restored})
+}
+
+// deleteTrashedLink Delete a Short URL in the trash for good
+//
+//	@Summary		Delete a Short URL in the trash for good
+//	@Description	Removes the link with its click history, this cannot be undone
+//	@Param			alias	path	string	true	"Short URL"
+//	@Param			domain	query	string	false	"Custom domain of the link"
+//	@Tags			protected
+//	@Security		BearerAuth
+//	@Produce		json
+//	@Success		200
+//	@Failure		400
+//	@Failure		404
+//	@Failure		500
+//	@Router			/api/v1/links/trash/{alias} [delete]
+func DeleteTrashedLink(c *fiber.Ctx, queries *database.Queries, ctx context.Context, policy *alias.Policy) error {
+	data, err := getOwnedTrashedLink(c, queries, ctx, policy, aliasParam(c, "alias"))
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	if err := queries.DeleteLink(ctx, data.ID); err != nil {
+		log.Print(err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Cannot delete short link"})
+	}
+
+	log.Println("Deleted a shortened link for good: ", data.ShortLink)
+	return c.JSON(fiber.Map{"Success": "Shortened link deleted for good"})
+}
@@ -0,0 +1,178 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"html/template"
+	"log"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/tin3ga/shortly/alias"
+	"github.com/tin3ga/shortly/geoip"
+	"github.com/tin3ga/shortly/internal/database"
+)
+
+var unlockPage = template.Must(template.New("unlock").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="robots" content="noindex">
+<title>Protected link</title>
+<style>
+body { font-family: sans-serif; display: flex; justify-content: center; margin-top: 15vh; }
+form { display: flex; flex-direction: column; gap: 0.75rem; width: 18rem; }
+.error { color: #b00020; }
+</style>
+</head>
+<body>
+<form method="post">
+<h1>Protected link</h1>
+<p>Enter the password to continue to <strong>/{{.Alias}}</strong></p>
+{{if .Error}}<p class="error">{{.Error}}</p>{{end}}
+<input type="password" name="password" autocomplete="current-password" autofocus required>
+<button type="submit">Unlock</button>
+</form>
+</body>
+</html>
+`))
+
+// renderUnlockPage serves the password form for a protected link
+func renderUnlockPage(c *fiber.Ctx, status int, alias string, message string) error {
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	c.Type("html", "utf-8")
+	return unlockPage.Execute(c.Status(status), fiber.Map{"Alias": alias, "Error": message})
+}
+
+// UnlockLimiterKey identifies the link of an unlock attempt for the wrong password limiter,
+// every spelling of the alias that finds the link (escaped, NFD or other case) shares one key
+func UnlockLimiterKey(c *fiber.Ctx) string {
+	return "unlock:" + normalizeHost(c.Hostname()) + "/" + alias.Key(alias.Normalize(aliasParam(c, "link")))
+}
+
+// unlockLink Unlock a password protected Short URL
+//
+//	@Summary		Unlock a password protected Short URL
+//	@Description	Redirects to the original URL when the password is correct
+//	@Accept			x-www-form-urlencoded
+//	@Produce		html
+//	@Param			link		path		string	true	"Short URL"
+//	@Param			password	formData	string	true	"Link password"
+//	@Success		303
+//	@Failure		401
+//	@Failure		404
+//	@Failure		410
+//	@Failure		429
+//	@Failure		451
+//	@Router			/{link} [post]
+func UnlockLink(c *fiber.Ctx, queries *database.Queries, ctx context.Context, rdb *redis.Client, ttl time.Duration, geo *geoip.Reader, notLiveURL, disabledURL string, policy *alias.Policy) error {
+	link := aliasParam(c, "link")
+
+	domainID, err := resolveDomain(ctx, queries, rdb, ttl, c.Hostname())
+	if err != nil {
+		log.Print(err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Internal server error"})
+	}
+
+	data, err := findLink(ctx, queries, policy, domainID, link)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "short url not found"})
+	}
+
+	if isExpired(data) {
+		return c.Status(fiber.StatusGone).JSON(fiber.Map{"error": "short url has expired"})
+	}
+
+	if !data.Enabled {
+		return disabledLink(c, disabledURL)
+	}
+
+	if isPending(data) {
+		return notLive(c, data, notLiveURL)
+	}
+
+	if isBlockedCountry(c, geo, data) {
+		return c.Status(fiber.StatusUnavailableForLegalReasons).JSON(fiber.Map{"error": "short url is not available in your country"})
+	}
+
+	if !data.PasswordHash.Valid {
+		return c.Redirect("/"+link, fiber.StatusSeeOther)
+	}
+
+	if !CheckPasswordHash(data.PasswordHash.String, c.FormValue("password")) {
+		log.Printf("Invalid password for protected link: %v", link)
+		return renderUnlockPage(c, fiber.StatusUnauthorized, link, "Incorrect password, try again")
+	}
+
+	if err := claimClick(ctx, queries, data); err != nil {
+		if errors.Is(err, errClickLimitReached) {
+			return c.Status(fiber.StatusGone).JSON(fiber.Map{"error": "short url has reached its click limit"})
+		}
+		log.Print(err)
+		if data.MaxClicks.Valid {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Internal server error"})
+		}
+	}
+
+	destination := visitorDestination(c, ctx, queries, geo, data)
+	log.Println("Redirecting to: ", destination)
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	return c.Redirect(destination, fiber.StatusSeeOther)
+}
+
+// setLinkPassword Set or remove the password of a Short URL
+//
+//	@Summary		Set or remove the password of a Short URL
+//	@Description	An empty password removes the protection
+//	@Param			alias		path	string			true	"Short URL"
+//	@Param			password	body	PasswordInput	true	"Link password"
+//	@Tags			protected
+//	@Security		BearerAuth
+//	@Success		200
+//	@Failure		400
+//	@Failure		404
+//	@Failure		500
+//	@Router			/api/v1/links/{alias}/password [put]
+func SetLinkPassword(c *fiber.Ctx, queries *database.Queries, ctx context.Context, rdb *redis.Client) error {
+	input := new(PasswordInput)
+
+	if err := c.BodyParser(input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Cannot parse JSON"})
+	}
+
+	data, err := getOwnedLink(c, queries, ctx, aliasParam(c, "alias"))
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	var passwordHash sql.NullString
+	if input.Password != "" {
+		hash, err := hashPassword(input.Password)
+		if err != nil {
+			log.Print(err)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Cannot set link password"})
+		}
+		passwordHash = sql.NullString{String: hash, Valid: true}
+	}
+
+	params := database.SetLinkPasswordParams{
+		ID:           data.ID,
+		PasswordHash: passwordHash,
+	}
+	if err := queries.SetLinkPassword(ctx, params); err != nil {
+		log.Print(err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Cannot set link password"})
+	}
+
+	// cached copies do not carry the password hash
+	invalidateCache(ctx, rdb, data)
+
+	if passwordHash.Valid {
+		return c.JSON(fiber.Map{"Success": "Link password set"})
+	}
+	return c.JSON(fiber.Map{"Success": "Link password removed"})
+}
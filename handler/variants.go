@@ -0,0 +1,234 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"log"
+	"math"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/tin3ga/shortly/internal/database"
+	"github.com/tin3ga/shortly/targeting"
+)
+
+// variantCookie remembers the variant a visitor was sent to when a link has sticky variants, there is one cookie
+// per link
+const (
+	variantCookie       = "shortly_variant"
+	variantCookieMaxAge = 30 * 24 * time.Hour
+)
+
+// Variants model info
+//
+//	@Description	Weighted destinations of a split test, e.g. weights 70 and 30
+//	@Description	Sticky visitors keep seeing the same variant through a cookie
+type VariantsModel struct {
+	Variants []targeting.Variant `json:"variants"`
+	Sticky   bool                `json:"sticky"`
+}
+
+// VariantStats compares the traffic a variant should get with the clicks it got
+type VariantStats struct {
+	Name   string `json:"name"`
+	Url    string `json:"url"`
+	Weight int    `json:"weight"`
+	// Expected share of the traffic in percent
+	Traffic_share float64 `json:"traffic_share"`
+	Clicks        int64   `json:"clicks"`
+	// Actual share of the clicks in percent
+	Click_share float64 `json:"click_share"`
+}
+
+// variantCookieName is keyed by link id with the cookie on every path, a path cookie would have to match the
+// percent encoded alias exactly and would miss other spellings of an alias when aliases ignore case
+func variantCookieName(linkID uuid.UUID) string {
+	return variantCookie + "_" + linkID.String()
+}
+
+// pickVariant chooses a split test variant for the visitor and counts the click on it, links without variants use their url
+func pickVariant(c *fiber.Ctx, ctx context.Context, queries *database.Queries, data database.Shortly) string {
+	var variants []targeting.Variant
+	if err := decodeRules(data.Variants, &variants); err != nil {
+		log.Print(err)
+	}
+
+	var sticky string
+	if data.StickyVariants {
+		sticky = c.Cookies(variantCookieName(data.ID))
+	}
+
+	variant, ok := targeting.Pick(variants, sticky)
+	if !ok {
+		return data.LongLink
+	}
+
+	if data.StickyVariants && variant.Name != sticky {
+		c.Cookie(&fiber.Cookie{
+			Name:     variantCookieName(data.ID),
+			Value:    variant.Name,
+			Path:     "/",
+			MaxAge:   int(variantCookieMaxAge.Seconds()),
+			HTTPOnly: true,
+			SameSite: fiber.CookieSameSiteLaxMode,
+		})
+	}
+
+	params := database.RecordVariantClickParams{LinkID: data.ID, Variant: variant.Name}
+	if err := queries.RecordVariantClick(ctx, params); err != nil {
+		log.Print(err)
+	}
+
+	return variant.Url
+}
+
+// percent returns part as a percentage of total rounded to one decimal
+func percent(part, total int64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return math.Round(float64(part)*1000/float64(total)) / 10
+}
+
+// setLinkVariants Replace the split test variants of a Short URL
+//
+//	@Summary		Replace the split test variants of a Short URL
+//	@Description	Visitors not matched by a targeting rule are spread over the variants by weight, an empty list ends the split test
+//	@Description	Clicks of variants that are kept are preserved, clicks of removed variants are deleted
+//	@Param			alias		path	string			true	"Short URL"
+//	@Param			variants	body	VariantsModel	true	"Split test variants"
+//	@Tags			protected
+//	@Security		BearerAuth
+//	@Produce		json
+//	@Success		200
+//	@Failure		400
+//	@Failure		404
+//	@Failure		500
+//	@Router			/api/v1/links/{alias}/variants [put]
+func SetLinkVariants(c *fiber.Ctx, db *sql.DB, queries *database.Queries, ctx context.Context, rdb *redis.Client, apiKey string) error {
+	input := new(VariantsModel)
+
+	if err := c.BodyParser(input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Cannot parse JSON"})
+	}
+
+	variants, err := targeting.NormalizeVariants(input.Variants)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	data, err := getOwnedLink(c, queries, ctx, aliasParam(c, "alias"))
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	names := make([]string, 0, len(variants))
+	for _, variant := range variants {
+		if !hasValidScheme(variant.Url) {
+			log.Printf("Invalid URL scheme: %v", variant.Url)
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "URL must start with https://", "url": variant.Url})
+		}
+		if _, err := scanLink(apiKey, variant.Url); err != nil {
+			return errorResponse(c, err)
+		}
+		names = append(names, variant.Name)
+	}
+
+	updated, err := setVariants(ctx, db, queries, data.ID, variants, names, input.Sticky)
+	if err != nil {
+		log.Print(err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Cannot set variants"})
+	}
+
+	invalidateCache(ctx, rdb, updated)
+
+	log.Printf("Set %v variants on: %v", len(variants), updated.ShortLink)
+	return c.JSON(fiber.Map{"Success": "Variants updated", "variants": variants, "sticky": updated.StickyVariants})
+}
+
+// setVariants stores the variants of a link and drops the clicks of variants that were removed
+func setVariants(ctx context.Context, db *sql.DB, queries *database.Queries, linkID uuid.UUID, variants []targeting.Variant, names []string, sticky bool) (database.Shortly, error) {
+	encoded, err := json.Marshal(variants)
+	if err != nil {
+		return database.Shortly{}, err
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return database.Shortly{}, err
+	}
+	defer tx.Rollback()
+
+	qtx := queries.WithTx(tx)
+
+	updated, err := qtx.SetLinkVariants(ctx, database.SetLinkVariantsParams{ID: linkID, Variants: encoded, StickyVariants: sticky})
+	if err != nil {
+		return database.Shortly{}, err
+	}
+
+	if err := qtx.DeleteStaleVariantClicks(ctx, database.DeleteStaleVariantClicksParams{LinkID: linkID, Variants: names}); err != nil {
+		return database.Shortly{}, err
+	}
+
+	return updated, tx.Commit()
+}
+
+// getLinkVariants Compare the variants of a Short URL
+//
+//	@Summary		Compare the variants of a Short URL
+//	@Description	Returns every variant with its expected traffic share and its actual clicks and click share
+//	@Param			alias	path	string	true	"Short URL"
+//	@Tags			protected
+//	@Security		BearerAuth
+//	@Produce		json
+//	@Success		200
+//	@Failure		400
+//	@Failure		404
+//	@Failure		500
+//	@Router			/api/v1/links/{alias}/variants [get]
+func GetLinkVariants(c *fiber.Ctx, queries *database.Queries, ctx context.Context) error {
+	data, err := getOwnedLink(c, queries, ctx, aliasParam(c, "alias"))
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	var variants []targeting.Variant
+	if err := decodeRules(data.Variants, &variants); err != nil {
+		log.Print(err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Cannot fetch variants"})
+	}
+
+	rows, err := queries.GetVariantClicks(ctx, data.ID)
+	if err != nil {
+		log.Print(err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Cannot fetch variants"})
+	}
+	clicks := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		clicks[row.Variant] = row.Clicks
+	}
+
+	var totalWeight, totalClicks int64
+	for _, variant := range variants {
+		totalWeight += int64(variant.Weight)
+		totalClicks += clicks[variant.Name]
+	}
+
+	stats := make([]VariantStats, 0, len(variants))
+	for _, variant := range variants {
+		stats = append(stats, VariantStats{
+			Name:          variant.Name,
+			Url:           variant.Url,
+			Weight:        variant.Weight,
+			Traffic_share: percent(int64(variant.Weight), totalWeight),
+			Clicks:        clicks[variant.Name],
+			Click_share:   percent(clicks[variant.Name], totalClicks),
+		})
+	}
+
+	return c.JSON(fiber.Map{"variants": stats, "sticky": data.StickyVariants, "total_clicks": totalClicks})
+}
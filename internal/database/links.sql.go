@@ -7,21 +7,91 @@ package database
 
 import (
 	"context"
+	"database/sql"
+	"encoding/json"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
+const archiveExpiredLinks = `-- name: ArchiveExpiredLinks :execrows
+WITH expired AS (
+    DELETE FROM shortly
+    WHERE expires_at IS NOT NULL AND expires_at <= NOW()
+    RETURNING id, user_id, short_link, long_link, click_count, created_at, expires_at, domain_id
+)
+INSERT INTO shortly_archive(id, user_id, short_link, long_link, click_count, created_at, expires_at, domain_id)
+SELECT id, user_id, short_link, long_link, click_count, created_at, expires_at, domain_id FROM expired
+`
+
+func (q *Queries) ArchiveExpiredLinks(ctx context.Context) (int64, error) {
+	result, err := q.db.ExecContext(ctx, archiveExpiredLinks)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const claimClick = `-- name: ClaimClick :one
+UPDATE shortly
+SET click_count = click_count + 1, updated_at = NOW()
+WHERE id = $1
+    AND (max_clicks IS NULL OR click_count < max_clicks)
+    AND (expires_at IS NULL OR expires_at > NOW())
+RETURNING click_count
+`
+
+func (q *Queries) ClaimClick(ctx context.Context, id uuid.UUID) (int32, error) {
+	row := q.db.QueryRowContext(ctx, claimClick, id)
+	var click_count int32
+	err := row.Scan(&click_count)
+	return click_count, err
+}
+
+const countQRScan = `-- name: CountQRScan :exec
+UPDATE shortly
+SET qr_scans = qr_scans + 1
+WHERE id = $1
+`
+
+func (q *Queries) CountQRScan(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, countQRScan, id)
+	return err
+}
+
 const createShortLink = `-- name: CreateShortLink :one
-INSERT INTO shortly(id, user_id, short_link, long_link)
-VALUES($1, $2, $3, $4)
-RETURNING id, user_id, short_link, long_link, click_count, created_at, updated_at
+INSERT INTO shortly(
+    id, user_id, short_link, long_link, expires_at, max_clicks, password_hash, domain_id, folder, notes, scan_verdict, redirect_type,
+    forward_query, utm_source, utm_medium, utm_campaign, utm_term, utm_content, activate_at, normalized_link, alias_key,
+    alias_key_unique
+)
+VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22)
+RETURNING id, user_id, short_link, long_link, click_count, created_at, updated_at, expires_at, max_clicks, password_hash, redirect_type, domain_id, folder, notes, title, description, favicon_url, og_title, og_description, og_image, metadata_fetched_at, scan_verdict, forward_query, utm_source, utm_medium, utm_campaign, utm_term, utm_content, targeting_rules, geo_rules, blocked_countries, variants, sticky_variants, activate_at, schedule, schedule_timezone, enabled, deleted_at, normalized_link, alias_key, qr_scans, alias_key_unique
 `
 
 type CreateShortLinkParams struct {
-	ID        uuid.UUID `json:"id"`
-	UserID    uuid.UUID `json:"user_id"`
-	ShortLink string    `json:"short_link"`
-	LongLink  string    `json:"long_link"`
+	ID             uuid.UUID      `json:"id"`
+	UserID         uuid.UUID      `json:"user_id"`
+	ShortLink      string         `json:"short_link"`
+	LongLink       string         `json:"long_link"`
+	ExpiresAt      sql.NullTime   `json:"expires_at"`
+	MaxClicks      sql.NullInt32  `json:"max_clicks"`
+	PasswordHash   sql.NullString `json:"password_hash"`
+	DomainID       uuid.NullUUID  `json:"domain_id"`
+	Folder         sql.NullString `json:"folder"`
+	Notes          sql.NullString `json:"notes"`
+	ScanVerdict    sql.NullString `json:"scan_verdict"`
+	RedirectType   sql.NullInt32  `json:"redirect_type"`
+	ForwardQuery   bool           `json:"forward_query"`
+	UtmSource      sql.NullString `json:"utm_source"`
+	UtmMedium      sql.NullString `json:"utm_medium"`
+	UtmCampaign    sql.NullString `json:"utm_campaign"`
+	UtmTerm        sql.NullString `json:"utm_term"`
+	UtmContent     sql.NullString `json:"utm_content"`
+	ActivateAt     sql.NullTime   `json:"activate_at"`
+	NormalizedLink sql.NullString `json:"normalized_link"`
+	AliasKey       string         `json:"alias_key"`
+	AliasKeyUnique bool           `json:"alias_key_unique"`
 }
 
 func (q *Queries) CreateShortLink(ctx context.Context, arg CreateShortLinkParams) (Shortly, error) {
@@ -30,6 +100,24 @@ func (q *Queries) CreateShortLink(ctx context.Context, arg CreateShortLinkParams
 		arg.UserID,
 		arg.ShortLink,
 		arg.LongLink,
+		arg.ExpiresAt,
+		arg.MaxClicks,
+		arg.PasswordHash,
+		arg.DomainID,
+		arg.Folder,
+		arg.Notes,
+		arg.ScanVerdict,
+		arg.RedirectType,
+		arg.ForwardQuery,
+		arg.UtmSource,
+		arg.UtmMedium,
+		arg.UtmCampaign,
+		arg.UtmTerm,
+		arg.UtmContent,
+		arg.ActivateAt,
+		arg.NormalizedLink,
+		arg.AliasKey,
+		arg.AliasKeyUnique,
 	)
 	var i Shortly
 	err := row.Scan(
@@ -40,41 +128,487 @@ func (q *Queries) CreateShortLink(ctx context.Context, arg CreateShortLinkParams
 		&i.ClickCount,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.ExpiresAt,
+		&i.MaxClicks,
+		&i.PasswordHash,
+		&i.RedirectType,
+		&i.DomainID,
+		&i.Folder,
+		&i.Notes,
+		&i.Title,
+		&i.Description,
+		&i.FaviconUrl,
+		&i.OgTitle,
+		&i.OgDescription,
+		&i.OgImage,
+		&i.MetadataFetchedAt,
+		&i.ScanVerdict,
+		&i.ForwardQuery,
+		&i.UtmSource,
+		&i.UtmMedium,
+		&i.UtmCampaign,
+		&i.UtmTerm,
+		&i.UtmContent,
+		&i.TargetingRules,
+		&i.GeoRules,
+		pq.Array(&i.BlockedCountries),
+		&i.Variants,
+		&i.StickyVariants,
+		&i.ActivateAt,
+		&i.Schedule,
+		&i.ScheduleTimezone,
+		&i.Enabled,
+		&i.DeletedAt,
+		&i.NormalizedLink,
+		&i.AliasKey,
+		&i.QrScans,
+		&i.AliasKeyUnique,
 	)
 	return i, err
 }
 
 const deleteLink = `-- name: DeleteLink :exec
-DELETE FROM shortly WHERE short_link = $1
+DELETE FROM shortly WHERE id = $1
 `
 
-func (q *Queries) DeleteLink(ctx context.Context, shortLink string) error {
-	_, err := q.db.ExecContext(ctx, deleteLink, shortLink)
+func (q *Queries) DeleteLink(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteLink, id)
 	return err
 }
 
-const getLinks = `-- name: GetLinks :many
-SELECT id, user_id, short_link, long_link, click_count, created_at, updated_at FROM shortly
+const findReusableLink = `-- name: FindReusableLink :one
+SELECT id, user_id, short_link, long_link, click_count, created_at, updated_at, expires_at, max_clicks, password_hash, redirect_type, domain_id, folder, notes, title, description, favicon_url, og_title, og_description, og_image, metadata_fetched_at, scan_verdict, forward_query, utm_source, utm_medium, utm_campaign, utm_term, utm_content, targeting_rules, geo_rules, blocked_countries, variants, sticky_variants, activate_at, schedule, schedule_timezone, enabled, deleted_at, normalized_link, alias_key, qr_scans, alias_key_unique FROM shortly
+WHERE user_id = $1
+    AND normalized_link = $2
+    AND domain_id IS NOT DISTINCT FROM $3
+    AND deleted_at IS NULL
+    AND enabled
+    AND password_hash IS NULL
+    AND (expires_at IS NULL OR expires_at > NOW())
+    AND (max_clicks IS NULL OR click_count < max_clicks)
+    AND (activate_at IS NULL OR activate_at <= NOW())
+    AND redirect_type IS NULL
+    AND NOT forward_query
+    AND utm_source IS NULL AND utm_medium IS NULL AND utm_campaign IS NULL AND utm_term IS NULL AND utm_content IS NULL
+    AND folder IS NULL
+    AND NOT EXISTS (SELECT 1 FROM link_tags WHERE link_tags.link_id = shortly.id)
+    AND targeting_rules = '[]' AND geo_rules = '[]' AND blocked_countries = '{}' AND variants = '[]' AND schedule = '[]'
 ORDER BY created_at DESC
+LIMIT 1
+`
+
+type FindReusableLinkParams struct {
+	UserID         uuid.UUID      `json:"user_id"`
+	NormalizedLink sql.NullString `json:"normalized_link"`
+	DomainID       uuid.NullUUID  `json:"domain_id"`
+}
+
+func (q *Queries) FindReusableLink(ctx context.Context, arg FindReusableLinkParams) (Shortly, error) {
+	row := q.db.QueryRowContext(ctx, findReusableLink, arg.UserID, arg.NormalizedLink, arg.DomainID)
+	var i Shortly
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.ShortLink,
+		&i.LongLink,
+		&i.ClickCount,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ExpiresAt,
+		&i.MaxClicks,
+		&i.PasswordHash,
+		&i.RedirectType,
+		&i.DomainID,
+		&i.Folder,
+		&i.Notes,
+		&i.Title,
+		&i.Description,
+		&i.FaviconUrl,
+		&i.OgTitle,
+		&i.OgDescription,
+		&i.OgImage,
+		&i.MetadataFetchedAt,
+		&i.ScanVerdict,
+		&i.ForwardQuery,
+		&i.UtmSource,
+		&i.UtmMedium,
+		&i.UtmCampaign,
+		&i.UtmTerm,
+		&i.UtmContent,
+		&i.TargetingRules,
+		&i.GeoRules,
+		pq.Array(&i.BlockedCountries),
+		&i.Variants,
+		&i.StickyVariants,
+		&i.ActivateAt,
+		&i.Schedule,
+		&i.ScheduleTimezone,
+		&i.Enabled,
+		&i.DeletedAt,
+		&i.NormalizedLink,
+		&i.AliasKey,
+		&i.QrScans,
+		&i.AliasKeyUnique,
+	)
+	return i, err
+}
+
+const getArchivedLink = `-- name: GetArchivedLink :one
+SELECT id, user_id, short_link, long_link, click_count, created_at, expires_at, archived_at, domain_id FROM shortly_archive
+WHERE short_link = $1 AND domain_id IS NOT DISTINCT FROM $2
+ORDER BY archived_at DESC
+LIMIT 1
+`
+
+type GetArchivedLinkParams struct {
+	ShortLink string        `json:"short_link"`
+	DomainID  uuid.NullUUID `json:"domain_id"`
+}
+
+func (q *Queries) GetArchivedLink(ctx context.Context, arg GetArchivedLinkParams) (ShortlyArchive, error) {
+	row := q.db.QueryRowContext(ctx, getArchivedLink, arg.ShortLink, arg.DomainID)
+	var i ShortlyArchive
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.ShortLink,
+		&i.LongLink,
+		&i.ClickCount,
+		&i.CreatedAt,
+		&i.ExpiresAt,
+		&i.ArchivedAt,
+		&i.DomainID,
+	)
+	return i, err
+}
+
+const getDomainLink = `-- name: GetDomainLink :one
+SELECT id, user_id, short_link, long_link, click_count, created_at, updated_at, expires_at, max_clicks, password_hash, redirect_type, domain_id, folder, notes, title, description, favicon_url, og_title, og_description, og_image, metadata_fetched_at, scan_verdict, forward_query, utm_source, utm_medium, utm_campaign, utm_term, utm_content, targeting_rules, geo_rules, blocked_countries, variants, sticky_variants, activate_at, schedule, schedule_timezone, enabled, deleted_at, normalized_link, alias_key, qr_scans, alias_key_unique FROM shortly
+WHERE short_link = $1 AND domain_id = $2 AND deleted_at IS NULL
+LIMIT 1
+`
+
+type GetDomainLinkParams struct {
+	ShortLink string        `json:"short_link"`
+	DomainID  uuid.NullUUID `json:"domain_id"`
+}
+
+func (q *Queries) GetDomainLink(ctx context.Context, arg GetDomainLinkParams) (Shortly, error) {
+	row := q.db.QueryRowContext(ctx, getDomainLink, arg.ShortLink, arg.DomainID)
+	var i Shortly
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.ShortLink,
+		&i.LongLink,
+		&i.ClickCount,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ExpiresAt,
+		&i.MaxClicks,
+		&i.PasswordHash,
+		&i.RedirectType,
+		&i.DomainID,
+		&i.Folder,
+		&i.Notes,
+		&i.Title,
+		&i.Description,
+		&i.FaviconUrl,
+		&i.OgTitle,
+		&i.OgDescription,
+		&i.OgImage,
+		&i.MetadataFetchedAt,
+		&i.ScanVerdict,
+		&i.ForwardQuery,
+		&i.UtmSource,
+		&i.UtmMedium,
+		&i.UtmCampaign,
+		&i.UtmTerm,
+		&i.UtmContent,
+		&i.TargetingRules,
+		&i.GeoRules,
+		pq.Array(&i.BlockedCountries),
+		&i.Variants,
+		&i.StickyVariants,
+		&i.ActivateAt,
+		&i.Schedule,
+		&i.ScheduleTimezone,
+		&i.Enabled,
+		&i.DeletedAt,
+		&i.NormalizedLink,
+		&i.AliasKey,
+		&i.QrScans,
+		&i.AliasKeyUnique,
+	)
+	return i, err
+}
+
+const getExistingAliases = `-- name: GetExistingAliases :many
+SELECT short_link FROM shortly
+WHERE short_link = ANY($1::text[])
+    AND domain_id IS NOT DISTINCT FROM $2
 `
 
-func (q *Queries) GetLinks(ctx context.Context) ([]Shortly, error) {
-	rows, err := q.db.QueryContext(ctx, getLinks)
+type GetExistingAliasesParams struct {
+	Aliases  []string      `json:"aliases"`
+	DomainID uuid.NullUUID `json:"domain_id"`
+}
+
+func (q *Queries) GetExistingAliases(ctx context.Context, arg GetExistingAliasesParams) ([]string, error) {
+	rows, err := q.db.QueryContext(ctx, getExistingAliases, pq.Array(arg.Aliases), arg.DomainID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []Shortly
+	var items []string
 	for rows.Next() {
-		var i Shortly
+		var short_link string
+		if err := rows.Scan(&short_link); err != nil {
+			return nil, err
+		}
+		items = append(items, short_link)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getExistingAliasKeys = `-- name: GetExistingAliasKeys :many
+SELECT alias_key FROM shortly
+WHERE alias_key = ANY($1::text[])
+    AND domain_id IS NOT DISTINCT FROM $2
+`
+
+type GetExistingAliasKeysParams struct {
+	AliasKeys []string      `json:"alias_keys"`
+	DomainID  uuid.NullUUID `json:"domain_id"`
+}
+
+func (q *Queries) GetExistingAliasKeys(ctx context.Context, arg GetExistingAliasKeysParams) ([]string, error) {
+	rows, err := q.db.QueryContext(ctx, getExistingAliasKeys, pq.Array(arg.AliasKeys), arg.DomainID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var alias_key string
+		if err := rows.Scan(&alias_key); err != nil {
+			return nil, err
+		}
+		items = append(items, alias_key)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getLinkByAliasKey = `-- name: GetLinkByAliasKey :one
+SELECT id, user_id, short_link, long_link, click_count, created_at, updated_at, expires_at, max_clicks, password_hash, redirect_type, domain_id, folder, notes, title, description, favicon_url, og_title, og_description, og_image, metadata_fetched_at, scan_verdict, forward_query, utm_source, utm_medium, utm_campaign, utm_term, utm_content, targeting_rules, geo_rules, blocked_countries, variants, sticky_variants, activate_at, schedule, schedule_timezone, enabled, deleted_at, normalized_link, alias_key, qr_scans, alias_key_unique FROM shortly
+WHERE alias_key = $1 AND domain_id IS NOT DISTINCT FROM $2 AND deleted_at IS NULL
+ORDER BY created_at
+LIMIT 1
+`
+
+type GetLinkByAliasKeyParams struct {
+	AliasKey string        `json:"alias_key"`
+	DomainID uuid.NullUUID `json:"domain_id"`
+}
+
+func (q *Queries) GetLinkByAliasKey(ctx context.Context, arg GetLinkByAliasKeyParams) (Shortly, error) {
+	row := q.db.QueryRowContext(ctx, getLinkByAliasKey, arg.AliasKey, arg.DomainID)
+	var i Shortly
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.ShortLink,
+		&i.LongLink,
+		&i.ClickCount,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ExpiresAt,
+		&i.MaxClicks,
+		&i.PasswordHash,
+		&i.RedirectType,
+		&i.DomainID,
+		&i.Folder,
+		&i.Notes,
+		&i.Title,
+		&i.Description,
+		&i.FaviconUrl,
+		&i.OgTitle,
+		&i.OgDescription,
+		&i.OgImage,
+		&i.MetadataFetchedAt,
+		&i.ScanVerdict,
+		&i.ForwardQuery,
+		&i.UtmSource,
+		&i.UtmMedium,
+		&i.UtmCampaign,
+		&i.UtmTerm,
+		&i.UtmContent,
+		&i.TargetingRules,
+		&i.GeoRules,
+		pq.Array(&i.BlockedCountries),
+		&i.Variants,
+		&i.StickyVariants,
+		&i.ActivateAt,
+		&i.Schedule,
+		&i.ScheduleTimezone,
+		&i.Enabled,
+		&i.DeletedAt,
+		&i.NormalizedLink,
+		&i.AliasKey,
+		&i.QrScans,
+		&i.AliasKeyUnique,
+	)
+	return i, err
+}
+
+const getLinkForUpdate = `-- name: GetLinkForUpdate :one
+SELECT id, user_id, short_link, long_link, click_count, created_at, updated_at, expires_at, max_clicks, password_hash, redirect_type, domain_id, folder, notes, title, description, favicon_url, og_title, og_description, og_image, metadata_fetched_at, scan_verdict, forward_query, utm_source, utm_medium, utm_campaign, utm_term, utm_content, targeting_rules, geo_rules, blocked_countries, variants, sticky_variants, activate_at, schedule, schedule_timezone, enabled, deleted_at, normalized_link, alias_key, qr_scans, alias_key_unique FROM shortly
+WHERE id = $1
+FOR UPDATE
+`
+
+func (q *Queries) GetLinkForUpdate(ctx context.Context, id uuid.UUID) (Shortly, error) {
+	row := q.db.QueryRowContext(ctx, getLinkForUpdate, id)
+	var i Shortly
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.ShortLink,
+		&i.LongLink,
+		&i.ClickCount,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ExpiresAt,
+		&i.MaxClicks,
+		&i.PasswordHash,
+		&i.RedirectType,
+		&i.DomainID,
+		&i.Folder,
+		&i.Notes,
+		&i.Title,
+		&i.Description,
+		&i.FaviconUrl,
+		&i.OgTitle,
+		&i.OgDescription,
+		&i.OgImage,
+		&i.MetadataFetchedAt,
+		&i.ScanVerdict,
+		&i.ForwardQuery,
+		&i.UtmSource,
+		&i.UtmMedium,
+		&i.UtmCampaign,
+		&i.UtmTerm,
+		&i.UtmContent,
+		&i.TargetingRules,
+		&i.GeoRules,
+		pq.Array(&i.BlockedCountries),
+		&i.Variants,
+		&i.StickyVariants,
+		&i.ActivateAt,
+		&i.Schedule,
+		&i.ScheduleTimezone,
+		&i.Enabled,
+		&i.DeletedAt,
+		&i.NormalizedLink,
+		&i.AliasKey,
+		&i.QrScans,
+		&i.AliasKeyUnique,
+	)
+	return i, err
+}
+
+const getLongLink = `-- name: GetLongLink :one
+SELECT id, user_id, short_link, long_link, click_count, created_at, updated_at, expires_at, max_clicks, password_hash, redirect_type, domain_id, folder, notes, title, description, favicon_url, og_title, og_description, og_image, metadata_fetched_at, scan_verdict, forward_query, utm_source, utm_medium, utm_campaign, utm_term, utm_content, targeting_rules, geo_rules, blocked_countries, variants, sticky_variants, activate_at, schedule, schedule_timezone, enabled, deleted_at, normalized_link, alias_key, qr_scans, alias_key_unique FROM shortly
+WHERE short_link = $1 AND domain_id IS NULL AND deleted_at IS NULL
+LIMIT 1
+`
+
+func (q *Queries) GetLongLink(ctx context.Context, shortLink string) (Shortly, error) {
+	row := q.db.QueryRowContext(ctx, getLongLink, shortLink)
+	var i Shortly
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.ShortLink,
+		&i.LongLink,
+		&i.ClickCount,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ExpiresAt,
+		&i.MaxClicks,
+		&i.PasswordHash,
+		&i.RedirectType,
+		&i.DomainID,
+		&i.Folder,
+		&i.Notes,
+		&i.Title,
+		&i.Description,
+		&i.FaviconUrl,
+		&i.OgTitle,
+		&i.OgDescription,
+		&i.OgImage,
+		&i.MetadataFetchedAt,
+		&i.ScanVerdict,
+		&i.ForwardQuery,
+		&i.UtmSource,
+		&i.UtmMedium,
+		&i.UtmCampaign,
+		&i.UtmTerm,
+		&i.UtmContent,
+		&i.TargetingRules,
+		&i.GeoRules,
+		pq.Array(&i.BlockedCountries),
+		&i.Variants,
+		&i.StickyVariants,
+		&i.ActivateAt,
+		&i.Schedule,
+		&i.ScheduleTimezone,
+		&i.Enabled,
+		&i.DeletedAt,
+		&i.NormalizedLink,
+		&i.AliasKey,
+		&i.QrScans,
+		&i.AliasKeyUnique,
+	)
+	return i, err
+}
+
+const getNonASCIIAliases = `-- name: GetNonASCIIAliases :many
+SELECT id, short_link, alias_key FROM shortly
+WHERE octet_length(short_link) <> char_length(short_link)
+`
+
+type GetNonASCIIAliasesRow struct {
+	ID        uuid.UUID `json:"id"`
+	ShortLink string    `json:"short_link"`
+	AliasKey  string    `json:"alias_key"`
+}
+
+func (q *Queries) GetNonASCIIAliases(ctx context.Context) ([]GetNonASCIIAliasesRow, error) {
+	rows, err := q.db.QueryContext(ctx, getNonASCIIAliases)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetNonASCIIAliasesRow
+	for rows.Next() {
+		var i GetNonASCIIAliasesRow
 		if err := rows.Scan(
 			&i.ID,
-			&i.UserID,
 			&i.ShortLink,
-			&i.LongLink,
-			&i.ClickCount,
-			&i.CreatedAt,
-			&i.UpdatedAt,
+			&i.AliasKey,
 		); err != nil {
 			return nil, err
 		}
@@ -89,14 +623,81 @@ func (q *Queries) GetLinks(ctx context.Context) ([]Shortly, error) {
 	return items, nil
 }
 
-const getLongLink = `-- name: GetLongLink :one
-SELECT id, user_id, short_link, long_link, click_count, created_at, updated_at FROM shortly
-WHERE short_link = $1
+const getTrashedLink = `-- name: GetTrashedLink :one
+SELECT id, user_id, short_link, long_link, click_count, created_at, updated_at, expires_at, max_clicks, password_hash, redirect_type, domain_id, folder, notes, title, description, favicon_url, og_title, og_description, og_image, metadata_fetched_at, scan_verdict, forward_query, utm_source, utm_medium, utm_campaign, utm_term, utm_content, targeting_rules, geo_rules, blocked_countries, variants, sticky_variants, activate_at, schedule, schedule_timezone, enabled, deleted_at, normalized_link, alias_key, qr_scans, alias_key_unique FROM shortly
+WHERE short_link = $1 AND domain_id IS NOT DISTINCT FROM $2 AND deleted_at IS NOT NULL
 LIMIT 1
 `
 
-func (q *Queries) GetLongLink(ctx context.Context, shortLink string) (Shortly, error) {
-	row := q.db.QueryRowContext(ctx, getLongLink, shortLink)
+type GetTrashedLinkParams struct {
+	ShortLink string        `json:"short_link"`
+	DomainID  uuid.NullUUID `json:"domain_id"`
+}
+
+func (q *Queries) GetTrashedLink(ctx context.Context, arg GetTrashedLinkParams) (Shortly, error) {
+	row := q.db.QueryRowContext(ctx, getTrashedLink, arg.ShortLink, arg.DomainID)
+	var i Shortly
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.ShortLink,
+		&i.LongLink,
+		&i.ClickCount,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ExpiresAt,
+		&i.MaxClicks,
+		&i.PasswordHash,
+		&i.RedirectType,
+		&i.DomainID,
+		&i.Folder,
+		&i.Notes,
+		&i.Title,
+		&i.Description,
+		&i.FaviconUrl,
+		&i.OgTitle,
+		&i.OgDescription,
+		&i.OgImage,
+		&i.MetadataFetchedAt,
+		&i.ScanVerdict,
+		&i.ForwardQuery,
+		&i.UtmSource,
+		&i.UtmMedium,
+		&i.UtmCampaign,
+		&i.UtmTerm,
+		&i.UtmContent,
+		&i.TargetingRules,
+		&i.GeoRules,
+		pq.Array(&i.BlockedCountries),
+		&i.Variants,
+		&i.StickyVariants,
+		&i.ActivateAt,
+		&i.Schedule,
+		&i.ScheduleTimezone,
+		&i.Enabled,
+		&i.DeletedAt,
+		&i.NormalizedLink,
+		&i.AliasKey,
+		&i.QrScans,
+		&i.AliasKeyUnique,
+	)
+	return i, err
+}
+
+const getTrashedLinkByAliasKey = `-- name: GetTrashedLinkByAliasKey :one
+SELECT id, user_id, short_link, long_link, click_count, created_at, updated_at, expires_at, max_clicks, password_hash, redirect_type, domain_id, folder, notes, title, description, favicon_url, og_title, og_description, og_image, metadata_fetched_at, scan_verdict, forward_query, utm_source, utm_medium, utm_campaign, utm_term, utm_content, targeting_rules, geo_rules, blocked_countries, variants, sticky_variants, activate_at, schedule, schedule_timezone, enabled, deleted_at, normalized_link, alias_key, qr_scans, alias_key_unique FROM shortly
+WHERE alias_key = $1 AND domain_id IS NOT DISTINCT FROM $2 AND deleted_at IS NOT NULL
+ORDER BY deleted_at DESC
+LIMIT 1
+`
+
+type GetTrashedLinkByAliasKeyParams struct {
+	AliasKey string        `json:"alias_key"`
+	DomainID uuid.NullUUID `json:"domain_id"`
+}
+
+func (q *Queries) GetTrashedLinkByAliasKey(ctx context.Context, arg GetTrashedLinkByAliasKeyParams) (Shortly, error) {
+	row := q.db.QueryRowContext(ctx, getTrashedLinkByAliasKey, arg.AliasKey, arg.DomainID)
 	var i Shortly
 	err := row.Scan(
 		&i.ID,
@@ -106,13 +707,120 @@ func (q *Queries) GetLongLink(ctx context.Context, shortLink string) (Shortly, e
 		&i.ClickCount,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.ExpiresAt,
+		&i.MaxClicks,
+		&i.PasswordHash,
+		&i.RedirectType,
+		&i.DomainID,
+		&i.Folder,
+		&i.Notes,
+		&i.Title,
+		&i.Description,
+		&i.FaviconUrl,
+		&i.OgTitle,
+		&i.OgDescription,
+		&i.OgImage,
+		&i.MetadataFetchedAt,
+		&i.ScanVerdict,
+		&i.ForwardQuery,
+		&i.UtmSource,
+		&i.UtmMedium,
+		&i.UtmCampaign,
+		&i.UtmTerm,
+		&i.UtmContent,
+		&i.TargetingRules,
+		&i.GeoRules,
+		pq.Array(&i.BlockedCountries),
+		&i.Variants,
+		&i.StickyVariants,
+		&i.ActivateAt,
+		&i.Schedule,
+		&i.ScheduleTimezone,
+		&i.Enabled,
+		&i.DeletedAt,
+		&i.NormalizedLink,
+		&i.AliasKey,
+		&i.QrScans,
+		&i.AliasKeyUnique,
 	)
 	return i, err
 }
 
+const getTrashedLinks = `-- name: GetTrashedLinks :many
+SELECT id, user_id, short_link, long_link, click_count, created_at, updated_at, expires_at, max_clicks, password_hash, redirect_type, domain_id, folder, notes, title, description, favicon_url, og_title, og_description, og_image, metadata_fetched_at, scan_verdict, forward_query, utm_source, utm_medium, utm_campaign, utm_term, utm_content, targeting_rules, geo_rules, blocked_countries, variants, sticky_variants, activate_at, schedule, schedule_timezone, enabled, deleted_at, normalized_link, alias_key, qr_scans, alias_key_unique FROM shortly
+WHERE user_id = $1 AND deleted_at IS NOT NULL
+ORDER BY deleted_at DESC
+`
+
+func (q *Queries) GetTrashedLinks(ctx context.Context, userID uuid.UUID) ([]Shortly, error) {
+	rows, err := q.db.QueryContext(ctx, getTrashedLinks, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Shortly
+	for rows.Next() {
+		var i Shortly
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.ShortLink,
+			&i.LongLink,
+			&i.ClickCount,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.ExpiresAt,
+			&i.MaxClicks,
+			&i.PasswordHash,
+			&i.RedirectType,
+			&i.DomainID,
+			&i.Folder,
+			&i.Notes,
+			&i.Title,
+			&i.Description,
+			&i.FaviconUrl,
+			&i.OgTitle,
+			&i.OgDescription,
+			&i.OgImage,
+			&i.MetadataFetchedAt,
+			&i.ScanVerdict,
+			&i.ForwardQuery,
+			&i.UtmSource,
+			&i.UtmMedium,
+			&i.UtmCampaign,
+			&i.UtmTerm,
+			&i.UtmContent,
+			&i.TargetingRules,
+			&i.GeoRules,
+			pq.Array(&i.BlockedCountries),
+			&i.Variants,
+			&i.StickyVariants,
+			&i.ActivateAt,
+			&i.Schedule,
+			&i.ScheduleTimezone,
+			&i.Enabled,
+			&i.DeletedAt,
+			&i.NormalizedLink,
+			&i.AliasKey,
+			&i.QrScans,
+			&i.AliasKeyUnique,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getUserLinks = `-- name: GetUserLinks :many
-SELECT id, user_id, short_link, long_link, click_count, created_at, updated_at FROM shortly
-WHERE user_id = $1
+SELECT id, user_id, short_link, long_link, click_count, created_at, updated_at, expires_at, max_clicks, password_hash, redirect_type, domain_id, folder, notes, title, description, favicon_url, og_title, og_description, og_image, metadata_fetched_at, scan_verdict, forward_query, utm_source, utm_medium, utm_campaign, utm_term, utm_content, targeting_rules, geo_rules, blocked_countries, variants, sticky_variants, activate_at, schedule, schedule_timezone, enabled, deleted_at, normalized_link, alias_key, qr_scans, alias_key_unique FROM shortly
+WHERE user_id = $1 AND deleted_at IS NULL
 ORDER BY created_at DESC
 `
 
@@ -133,6 +841,41 @@ func (q *Queries) GetUserLinks(ctx context.Context, userID uuid.UUID) ([]Shortly
 			&i.ClickCount,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.ExpiresAt,
+			&i.MaxClicks,
+			&i.PasswordHash,
+			&i.RedirectType,
+			&i.DomainID,
+			&i.Folder,
+			&i.Notes,
+			&i.Title,
+			&i.Description,
+			&i.FaviconUrl,
+			&i.OgTitle,
+			&i.OgDescription,
+			&i.OgImage,
+			&i.MetadataFetchedAt,
+			&i.ScanVerdict,
+			&i.ForwardQuery,
+			&i.UtmSource,
+			&i.UtmMedium,
+			&i.UtmCampaign,
+			&i.UtmTerm,
+			&i.UtmContent,
+			&i.TargetingRules,
+			&i.GeoRules,
+			pq.Array(&i.BlockedCountries),
+			&i.Variants,
+			&i.StickyVariants,
+			&i.ActivateAt,
+			&i.Schedule,
+			&i.ScheduleTimezone,
+			&i.Enabled,
+			&i.DeletedAt,
+			&i.NormalizedLink,
+			&i.AliasKey,
+			&i.QrScans,
+			&i.AliasKeyUnique,
 		); err != nil {
 			return nil, err
 		}
@@ -147,13 +890,1158 @@ func (q *Queries) GetUserLinks(ctx context.Context, userID uuid.UUID) ([]Shortly
 	return items, nil
 }
 
-const incrementClickCount = `-- name: IncrementClickCount :exec
-UPDATE shortly
-SET click_count = click_count + 1, updated_at = NOW()
-WHERE short_link = $1
+const listLinksByAlias = `-- name: ListLinksByAlias :many
+SELECT id, user_id, short_link, long_link, click_count, created_at, updated_at, expires_at, max_clicks, password_hash, redirect_type, domain_id, folder, notes, title, description, favicon_url, og_title, og_description, og_image, metadata_fetched_at, scan_verdict, forward_query, utm_source, utm_medium, utm_campaign, utm_term, utm_content, targeting_rules, geo_rules, blocked_countries, variants, sticky_variants, activate_at, schedule, schedule_timezone, enabled, deleted_at, normalized_link, alias_key, qr_scans, alias_key_unique FROM shortly
+WHERE deleted_at IS NULL
+    AND ($1::uuid IS NULL OR user_id = $1)
+    AND ($2::text IS NULL OR folder = $2)
+    AND ($3::text IS NULL OR EXISTS (
+        SELECT 1 FROM link_tags
+        JOIN tags ON tags.id = link_tags.tag_id
+        WHERE link_tags.link_id = shortly.id AND tags.name = $3
+    ))
+    AND ($4::timestamp IS NULL OR created_at >= $4)
+    AND ($5::timestamp IS NULL OR created_at < $5)
+    AND ($6::text IS NULL
+        OR lower(substring(long_link from '^[a-zA-Z][a-zA-Z0-9+.-]*://([^/?#:]+)')) = $6)
+    AND ($7::text IS NULL
+        OR (short_link, id) > ($7, $8::uuid))
+ORDER BY short_link ASC, id ASC
+LIMIT $9
 `
 
-func (q *Queries) IncrementClickCount(ctx context.Context, shortLink string) error {
-	_, err := q.db.ExecContext(ctx, incrementClickCount, shortLink)
-	return err
+type ListLinksByAliasParams struct {
+	UserID          uuid.NullUUID  `json:"user_id"`
+	Folder          sql.NullString `json:"folder"`
+	Tag             sql.NullString `json:"tag"`
+	CreatedFrom     sql.NullTime   `json:"created_from"`
+	CreatedTo       sql.NullTime   `json:"created_to"`
+	DestinationHost sql.NullString `json:"destination_host"`
+	CursorAlias     sql.NullString `json:"cursor_alias"`
+	CursorID        uuid.NullUUID  `json:"cursor_id"`
+	PageLimit       int32          `json:"page_limit"`
+}
+
+func (q *Queries) ListLinksByAlias(ctx context.Context, arg ListLinksByAliasParams) ([]Shortly, error) {
+	rows, err := q.db.QueryContext(ctx, listLinksByAlias,
+		arg.UserID,
+		arg.Folder,
+		arg.Tag,
+		arg.CreatedFrom,
+		arg.CreatedTo,
+		arg.DestinationHost,
+		arg.CursorAlias,
+		arg.CursorID,
+		arg.PageLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Shortly
+	for rows.Next() {
+		var i Shortly
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.ShortLink,
+			&i.LongLink,
+			&i.ClickCount,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.ExpiresAt,
+			&i.MaxClicks,
+			&i.PasswordHash,
+			&i.RedirectType,
+			&i.DomainID,
+			&i.Folder,
+			&i.Notes,
+			&i.Title,
+			&i.Description,
+			&i.FaviconUrl,
+			&i.OgTitle,
+			&i.OgDescription,
+			&i.OgImage,
+			&i.MetadataFetchedAt,
+			&i.ScanVerdict,
+			&i.ForwardQuery,
+			&i.UtmSource,
+			&i.UtmMedium,
+			&i.UtmCampaign,
+			&i.UtmTerm,
+			&i.UtmContent,
+			&i.TargetingRules,
+			&i.GeoRules,
+			pq.Array(&i.BlockedCountries),
+			&i.Variants,
+			&i.StickyVariants,
+			&i.ActivateAt,
+			&i.Schedule,
+			&i.ScheduleTimezone,
+			&i.Enabled,
+			&i.DeletedAt,
+			&i.NormalizedLink,
+			&i.AliasKey,
+			&i.QrScans,
+			&i.AliasKeyUnique,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listLinksByClicks = `-- name: ListLinksByClicks :many
+SELECT id, user_id, short_link, long_link, click_count, created_at, updated_at, expires_at, max_clicks, password_hash, redirect_type, domain_id, folder, notes, title, description, favicon_url, og_title, og_description, og_image, metadata_fetched_at, scan_verdict, forward_query, utm_source, utm_medium, utm_campaign, utm_term, utm_content, targeting_rules, geo_rules, blocked_countries, variants, sticky_variants, activate_at, schedule, schedule_timezone, enabled, deleted_at, normalized_link, alias_key, qr_scans, alias_key_unique FROM shortly
+WHERE deleted_at IS NULL
+    AND ($1::uuid IS NULL OR user_id = $1)
+    AND ($2::text IS NULL OR folder = $2)
+    AND ($3::text IS NULL OR EXISTS (
+        SELECT 1 FROM link_tags
+        JOIN tags ON tags.id = link_tags.tag_id
+        WHERE link_tags.link_id = shortly.id AND tags.name = $3
+    ))
+    AND ($4::timestamp IS NULL OR created_at >= $4)
+    AND ($5::timestamp IS NULL OR created_at < $5)
+    AND ($6::text IS NULL
+        OR lower(substring(long_link from '^[a-zA-Z][a-zA-Z0-9+.-]*://([^/?#:]+)')) = $6)
+    AND ($7::int IS NULL
+        OR (click_count, id) < ($7, $8::uuid))
+ORDER BY click_count DESC, id DESC
+LIMIT $9
+`
+
+type ListLinksByClicksParams struct {
+	UserID          uuid.NullUUID  `json:"user_id"`
+	Folder          sql.NullString `json:"folder"`
+	Tag             sql.NullString `json:"tag"`
+	CreatedFrom     sql.NullTime   `json:"created_from"`
+	CreatedTo       sql.NullTime   `json:"created_to"`
+	DestinationHost sql.NullString `json:"destination_host"`
+	CursorClicks    sql.NullInt32  `json:"cursor_clicks"`
+	CursorID        uuid.NullUUID  `json:"cursor_id"`
+	PageLimit       int32          `json:"page_limit"`
+}
+
+func (q *Queries) ListLinksByClicks(ctx context.Context, arg ListLinksByClicksParams) ([]Shortly, error) {
+	rows, err := q.db.QueryContext(ctx, listLinksByClicks,
+		arg.UserID,
+		arg.Folder,
+		arg.Tag,
+		arg.CreatedFrom,
+		arg.CreatedTo,
+		arg.DestinationHost,
+		arg.CursorClicks,
+		arg.CursorID,
+		arg.PageLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Shortly
+	for rows.Next() {
+		var i Shortly
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.ShortLink,
+			&i.LongLink,
+			&i.ClickCount,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.ExpiresAt,
+			&i.MaxClicks,
+			&i.PasswordHash,
+			&i.RedirectType,
+			&i.DomainID,
+			&i.Folder,
+			&i.Notes,
+			&i.Title,
+			&i.Description,
+			&i.FaviconUrl,
+			&i.OgTitle,
+			&i.OgDescription,
+			&i.OgImage,
+			&i.MetadataFetchedAt,
+			&i.ScanVerdict,
+			&i.ForwardQuery,
+			&i.UtmSource,
+			&i.UtmMedium,
+			&i.UtmCampaign,
+			&i.UtmTerm,
+			&i.UtmContent,
+			&i.TargetingRules,
+			&i.GeoRules,
+			pq.Array(&i.BlockedCountries),
+			&i.Variants,
+			&i.StickyVariants,
+			&i.ActivateAt,
+			&i.Schedule,
+			&i.ScheduleTimezone,
+			&i.Enabled,
+			&i.DeletedAt,
+			&i.NormalizedLink,
+			&i.AliasKey,
+			&i.QrScans,
+			&i.AliasKeyUnique,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listLinksByCreated = `-- name: ListLinksByCreated :many
+SELECT id, user_id, short_link, long_link, click_count, created_at, updated_at, expires_at, max_clicks, password_hash, redirect_type, domain_id, folder, notes, title, description, favicon_url, og_title, og_description, og_image, metadata_fetched_at, scan_verdict, forward_query, utm_source, utm_medium, utm_campaign, utm_term, utm_content, targeting_rules, geo_rules, blocked_countries, variants, sticky_variants, activate_at, schedule, schedule_timezone, enabled, deleted_at, normalized_link, alias_key, qr_scans, alias_key_unique FROM shortly
+WHERE deleted_at IS NULL
+    AND ($1::uuid IS NULL OR user_id = $1)
+    AND ($2::text IS NULL OR folder = $2)
+    AND ($3::text IS NULL OR EXISTS (
+        SELECT 1 FROM link_tags
+        JOIN tags ON tags.id = link_tags.tag_id
+        WHERE link_tags.link_id = shortly.id AND tags.name = $3
+    ))
+    AND ($4::timestamp IS NULL OR created_at >= $4)
+    AND ($5::timestamp IS NULL OR created_at < $5)
+    AND ($6::text IS NULL
+        OR lower(substring(long_link from '^[a-zA-Z][a-zA-Z0-9+.-]*://([^/?#:]+)')) = $6)
+    AND ($7::timestamp IS NULL
+        OR (created_at, id) < ($7, $8::uuid))
+ORDER BY created_at DESC, id DESC
+LIMIT $9
+`
+
+type ListLinksByCreatedParams struct {
+	UserID          uuid.NullUUID  `json:"user_id"`
+	Folder          sql.NullString `json:"folder"`
+	Tag             sql.NullString `json:"tag"`
+	CreatedFrom     sql.NullTime   `json:"created_from"`
+	CreatedTo       sql.NullTime   `json:"created_to"`
+	DestinationHost sql.NullString `json:"destination_host"`
+	CursorCreatedAt sql.NullTime   `json:"cursor_created_at"`
+	CursorID        uuid.NullUUID  `json:"cursor_id"`
+	PageLimit       int32          `json:"page_limit"`
+}
+
+func (q *Queries) ListLinksByCreated(ctx context.Context, arg ListLinksByCreatedParams) ([]Shortly, error) {
+	rows, err := q.db.QueryContext(ctx, listLinksByCreated,
+		arg.UserID,
+		arg.Folder,
+		arg.Tag,
+		arg.CreatedFrom,
+		arg.CreatedTo,
+		arg.DestinationHost,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.PageLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Shortly
+	for rows.Next() {
+		var i Shortly
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.ShortLink,
+			&i.LongLink,
+			&i.ClickCount,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.ExpiresAt,
+			&i.MaxClicks,
+			&i.PasswordHash,
+			&i.RedirectType,
+			&i.DomainID,
+			&i.Folder,
+			&i.Notes,
+			&i.Title,
+			&i.Description,
+			&i.FaviconUrl,
+			&i.OgTitle,
+			&i.OgDescription,
+			&i.OgImage,
+			&i.MetadataFetchedAt,
+			&i.ScanVerdict,
+			&i.ForwardQuery,
+			&i.UtmSource,
+			&i.UtmMedium,
+			&i.UtmCampaign,
+			&i.UtmTerm,
+			&i.UtmContent,
+			&i.TargetingRules,
+			&i.GeoRules,
+			pq.Array(&i.BlockedCountries),
+			&i.Variants,
+			&i.StickyVariants,
+			&i.ActivateAt,
+			&i.Schedule,
+			&i.ScheduleTimezone,
+			&i.Enabled,
+			&i.DeletedAt,
+			&i.NormalizedLink,
+			&i.AliasKey,
+			&i.QrScans,
+			&i.AliasKeyUnique,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const nextAliasSequence = `-- name: NextAliasSequence :one
+SELECT nextval('alias_sequence')::bigint AS next
+`
+
+func (q *Queries) NextAliasSequence(ctx context.Context) (int64, error) {
+	row := q.db.QueryRowContext(ctx, nextAliasSequence)
+	var next int64
+	err := row.Scan(&next)
+	return next, err
+}
+
+const purgeExpiredLinks = `-- name: PurgeExpiredLinks :execrows
+DELETE FROM shortly
+WHERE expires_at IS NOT NULL AND expires_at <= NOW()
+`
+
+func (q *Queries) PurgeExpiredLinks(ctx context.Context) (int64, error) {
+	result, err := q.db.ExecContext(ctx, purgeExpiredLinks)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const purgeTrashedDomainLinks = `-- name: PurgeTrashedDomainLinks :execrows
+DELETE FROM shortly
+WHERE domain_id = $1 AND deleted_at IS NOT NULL
+`
+
+func (q *Queries) PurgeTrashedDomainLinks(ctx context.Context, domainID uuid.NullUUID) (int64, error) {
+	result, err := q.db.ExecContext(ctx, purgeTrashedDomainLinks, domainID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const purgeTrashedLinks = `-- name: PurgeTrashedLinks :execrows
+DELETE FROM shortly
+WHERE deleted_at IS NOT NULL AND deleted_at <= NOW() - $1::bigint * INTERVAL '1 second'
+`
+
+func (q *Queries) PurgeTrashedLinks(ctx context.Context, retentionSeconds int64) (int64, error) {
+	result, err := q.db.ExecContext(ctx, purgeTrashedLinks, retentionSeconds)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const restoreLink = `-- name: RestoreLink :one
+UPDATE shortly
+SET deleted_at = NULL, updated_at = NOW()
+WHERE id = $1 AND deleted_at IS NOT NULL
+RETURNING id, user_id, short_link, long_link, click_count, created_at, updated_at, expires_at, max_clicks, password_hash, redirect_type, domain_id, folder, notes, title, description, favicon_url, og_title, og_description, og_image, metadata_fetched_at, scan_verdict, forward_query, utm_source, utm_medium, utm_campaign, utm_term, utm_content, targeting_rules, geo_rules, blocked_countries, variants, sticky_variants, activate_at, schedule, schedule_timezone, enabled, deleted_at, normalized_link, alias_key, qr_scans, alias_key_unique
+`
+
+func (q *Queries) RestoreLink(ctx context.Context, id uuid.UUID) (Shortly, error) {
+	row := q.db.QueryRowContext(ctx, restoreLink, id)
+	var i Shortly
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.ShortLink,
+		&i.LongLink,
+		&i.ClickCount,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ExpiresAt,
+		&i.MaxClicks,
+		&i.PasswordHash,
+		&i.RedirectType,
+		&i.DomainID,
+		&i.Folder,
+		&i.Notes,
+		&i.Title,
+		&i.Description,
+		&i.FaviconUrl,
+		&i.OgTitle,
+		&i.OgDescription,
+		&i.OgImage,
+		&i.MetadataFetchedAt,
+		&i.ScanVerdict,
+		&i.ForwardQuery,
+		&i.UtmSource,
+		&i.UtmMedium,
+		&i.UtmCampaign,
+		&i.UtmTerm,
+		&i.UtmContent,
+		&i.TargetingRules,
+		&i.GeoRules,
+		pq.Array(&i.BlockedCountries),
+		&i.Variants,
+		&i.StickyVariants,
+		&i.ActivateAt,
+		&i.Schedule,
+		&i.ScheduleTimezone,
+		&i.Enabled,
+		&i.DeletedAt,
+		&i.NormalizedLink,
+		&i.AliasKey,
+		&i.QrScans,
+		&i.AliasKeyUnique,
+	)
+	return i, err
+}
+
+const setAliasKey = `-- name: SetAliasKey :exec
+UPDATE shortly
+SET alias_key = $2
+WHERE id = $1
+`
+
+type SetAliasKeyParams struct {
+	ID       uuid.UUID `json:"id"`
+	AliasKey string    `json:"alias_key"`
+}
+
+func (q *Queries) SetAliasKey(ctx context.Context, arg SetAliasKeyParams) error {
+	_, err := q.db.ExecContext(ctx, setAliasKey, arg.ID, arg.AliasKey)
+	return err
+}
+
+const setLinkActivation = `-- name: SetLinkActivation :one
+UPDATE shortly
+SET activate_at = $2,
+    updated_at = NOW()
+WHERE id = $1
+RETURNING id, user_id, short_link, long_link, click_count, created_at, updated_at, expires_at, max_clicks, password_hash, redirect_type, domain_id, folder, notes, title, description, favicon_url, og_title, og_description, og_image, metadata_fetched_at, scan_verdict, forward_query, utm_source, utm_medium, utm_campaign, utm_term, utm_content, targeting_rules, geo_rules, blocked_countries, variants, sticky_variants, activate_at, schedule, schedule_timezone, enabled, deleted_at, normalized_link, alias_key, qr_scans, alias_key_unique
+`
+
+type SetLinkActivationParams struct {
+	ID         uuid.UUID    `json:"id"`
+	ActivateAt sql.NullTime `json:"activate_at"`
+}
+
+func (q *Queries) SetLinkActivation(ctx context.Context, arg SetLinkActivationParams) (Shortly, error) {
+	row := q.db.QueryRowContext(ctx, setLinkActivation, arg.ID, arg.ActivateAt)
+	var i Shortly
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.ShortLink,
+		&i.LongLink,
+		&i.ClickCount,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ExpiresAt,
+		&i.MaxClicks,
+		&i.PasswordHash,
+		&i.RedirectType,
+		&i.DomainID,
+		&i.Folder,
+		&i.Notes,
+		&i.Title,
+		&i.Description,
+		&i.FaviconUrl,
+		&i.OgTitle,
+		&i.OgDescription,
+		&i.OgImage,
+		&i.MetadataFetchedAt,
+		&i.ScanVerdict,
+		&i.ForwardQuery,
+		&i.UtmSource,
+		&i.UtmMedium,
+		&i.UtmCampaign,
+		&i.UtmTerm,
+		&i.UtmContent,
+		&i.TargetingRules,
+		&i.GeoRules,
+		pq.Array(&i.BlockedCountries),
+		&i.Variants,
+		&i.StickyVariants,
+		&i.ActivateAt,
+		&i.Schedule,
+		&i.ScheduleTimezone,
+		&i.Enabled,
+		&i.DeletedAt,
+		&i.NormalizedLink,
+		&i.AliasKey,
+		&i.QrScans,
+		&i.AliasKeyUnique,
+	)
+	return i, err
+}
+
+const setLinkEnabled = `-- name: SetLinkEnabled :one
+UPDATE shortly
+SET enabled = $2, updated_at = NOW()
+WHERE id = $1
+RETURNING id, user_id, short_link, long_link, click_count, created_at, updated_at, expires_at, max_clicks, password_hash, redirect_type, domain_id, folder, notes, title, description, favicon_url, og_title, og_description, og_image, metadata_fetched_at, scan_verdict, forward_query, utm_source, utm_medium, utm_campaign, utm_term, utm_content, targeting_rules, geo_rules, blocked_countries, variants, sticky_variants, activate_at, schedule, schedule_timezone, enabled, deleted_at, normalized_link, alias_key, qr_scans, alias_key_unique
+`
+
+type SetLinkEnabledParams struct {
+	ID      uuid.UUID `json:"id"`
+	Enabled bool      `json:"enabled"`
+}
+
+func (q *Queries) SetLinkEnabled(ctx context.Context, arg SetLinkEnabledParams) (Shortly, error) {
+	row := q.db.QueryRowContext(ctx, setLinkEnabled, arg.ID, arg.Enabled)
+	var i Shortly
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.ShortLink,
+		&i.LongLink,
+		&i.ClickCount,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ExpiresAt,
+		&i.MaxClicks,
+		&i.PasswordHash,
+		&i.RedirectType,
+		&i.DomainID,
+		&i.Folder,
+		&i.Notes,
+		&i.Title,
+		&i.Description,
+		&i.FaviconUrl,
+		&i.OgTitle,
+		&i.OgDescription,
+		&i.OgImage,
+		&i.MetadataFetchedAt,
+		&i.ScanVerdict,
+		&i.ForwardQuery,
+		&i.UtmSource,
+		&i.UtmMedium,
+		&i.UtmCampaign,
+		&i.UtmTerm,
+		&i.UtmContent,
+		&i.TargetingRules,
+		&i.GeoRules,
+		pq.Array(&i.BlockedCountries),
+		&i.Variants,
+		&i.StickyVariants,
+		&i.ActivateAt,
+		&i.Schedule,
+		&i.ScheduleTimezone,
+		&i.Enabled,
+		&i.DeletedAt,
+		&i.NormalizedLink,
+		&i.AliasKey,
+		&i.QrScans,
+		&i.AliasKeyUnique,
+	)
+	return i, err
+}
+
+const setLinkFolder = `-- name: SetLinkFolder :one
+UPDATE shortly
+SET folder = $2, updated_at = NOW()
+WHERE id = $1
+RETURNING id, user_id, short_link, long_link, click_count, created_at, updated_at, expires_at, max_clicks, password_hash, redirect_type, domain_id, folder, notes, title, description, favicon_url, og_title, og_description, og_image, metadata_fetched_at, scan_verdict, forward_query, utm_source, utm_medium, utm_campaign, utm_term, utm_content, targeting_rules, geo_rules, blocked_countries, variants, sticky_variants, activate_at, schedule, schedule_timezone, enabled, deleted_at, normalized_link, alias_key, qr_scans, alias_key_unique
+`
+
+type SetLinkFolderParams struct {
+	ID     uuid.UUID      `json:"id"`
+	Folder sql.NullString `json:"folder"`
+}
+
+func (q *Queries) SetLinkFolder(ctx context.Context, arg SetLinkFolderParams) (Shortly, error) {
+	row := q.db.QueryRowContext(ctx, setLinkFolder, arg.ID, arg.Folder)
+	var i Shortly
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.ShortLink,
+		&i.LongLink,
+		&i.ClickCount,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ExpiresAt,
+		&i.MaxClicks,
+		&i.PasswordHash,
+		&i.RedirectType,
+		&i.DomainID,
+		&i.Folder,
+		&i.Notes,
+		&i.Title,
+		&i.Description,
+		&i.FaviconUrl,
+		&i.OgTitle,
+		&i.OgDescription,
+		&i.OgImage,
+		&i.MetadataFetchedAt,
+		&i.ScanVerdict,
+		&i.ForwardQuery,
+		&i.UtmSource,
+		&i.UtmMedium,
+		&i.UtmCampaign,
+		&i.UtmTerm,
+		&i.UtmContent,
+		&i.TargetingRules,
+		&i.GeoRules,
+		pq.Array(&i.BlockedCountries),
+		&i.Variants,
+		&i.StickyVariants,
+		&i.ActivateAt,
+		&i.Schedule,
+		&i.ScheduleTimezone,
+		&i.Enabled,
+		&i.DeletedAt,
+		&i.NormalizedLink,
+		&i.AliasKey,
+		&i.QrScans,
+		&i.AliasKeyUnique,
+	)
+	return i, err
+}
+
+const setLinkGeoRules = `-- name: SetLinkGeoRules :one
+UPDATE shortly
+SET geo_rules = $2,
+    blocked_countries = $3,
+    updated_at = NOW()
+WHERE id = $1
+RETURNING id, user_id, short_link, long_link, click_count, created_at, updated_at, expires_at, max_clicks, password_hash, redirect_type, domain_id, folder, notes, title, description, favicon_url, og_title, og_description, og_image, metadata_fetched_at, scan_verdict, forward_query, utm_source, utm_medium, utm_campaign, utm_term, utm_content, targeting_rules, geo_rules, blocked_countries, variants, sticky_variants, activate_at, schedule, schedule_timezone, enabled, deleted_at, normalized_link, alias_key, qr_scans, alias_key_unique
+`
+
+type SetLinkGeoRulesParams struct {
+	ID               uuid.UUID       `json:"id"`
+	GeoRules         json.RawMessage `json:"geo_rules"`
+	BlockedCountries []string        `json:"blocked_countries"`
+}
+
+func (q *Queries) SetLinkGeoRules(ctx context.Context, arg SetLinkGeoRulesParams) (Shortly, error) {
+	row := q.db.QueryRowContext(ctx, setLinkGeoRules, arg.ID, arg.GeoRules, pq.Array(arg.BlockedCountries))
+	var i Shortly
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.ShortLink,
+		&i.LongLink,
+		&i.ClickCount,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ExpiresAt,
+		&i.MaxClicks,
+		&i.PasswordHash,
+		&i.RedirectType,
+		&i.DomainID,
+		&i.Folder,
+		&i.Notes,
+		&i.Title,
+		&i.Description,
+		&i.FaviconUrl,
+		&i.OgTitle,
+		&i.OgDescription,
+		&i.OgImage,
+		&i.MetadataFetchedAt,
+		&i.ScanVerdict,
+		&i.ForwardQuery,
+		&i.UtmSource,
+		&i.UtmMedium,
+		&i.UtmCampaign,
+		&i.UtmTerm,
+		&i.UtmContent,
+		&i.TargetingRules,
+		&i.GeoRules,
+		pq.Array(&i.BlockedCountries),
+		&i.Variants,
+		&i.StickyVariants,
+		&i.ActivateAt,
+		&i.Schedule,
+		&i.ScheduleTimezone,
+		&i.Enabled,
+		&i.DeletedAt,
+		&i.NormalizedLink,
+		&i.AliasKey,
+		&i.QrScans,
+		&i.AliasKeyUnique,
+	)
+	return i, err
+}
+
+const setLinkMetadata = `-- name: SetLinkMetadata :exec
+UPDATE shortly
+SET title = $2,
+    description = $3,
+    favicon_url = $4,
+    og_title = $5,
+    og_description = $6,
+    og_image = $7,
+    metadata_fetched_at = NOW()
+WHERE id = $1
+`
+
+type SetLinkMetadataParams struct {
+	ID            uuid.UUID      `json:"id"`
+	Title         sql.NullString `json:"title"`
+	Description   sql.NullString `json:"description"`
+	FaviconUrl    sql.NullString `json:"favicon_url"`
+	OgTitle       sql.NullString `json:"og_title"`
+	OgDescription sql.NullString `json:"og_description"`
+	OgImage       sql.NullString `json:"og_image"`
+}
+
+func (q *Queries) SetLinkMetadata(ctx context.Context, arg SetLinkMetadataParams) error {
+	_, err := q.db.ExecContext(ctx, setLinkMetadata,
+		arg.ID,
+		arg.Title,
+		arg.Description,
+		arg.FaviconUrl,
+		arg.OgTitle,
+		arg.OgDescription,
+		arg.OgImage,
+	)
+	return err
+}
+
+const setLinkNotes = `-- name: SetLinkNotes :one
+UPDATE shortly
+SET notes = $2, updated_at = NOW()
+WHERE id = $1
+RETURNING id, user_id, short_link, long_link, click_count, created_at, updated_at, expires_at, max_clicks, password_hash, redirect_type, domain_id, folder, notes, title, description, favicon_url, og_title, og_description, og_image, metadata_fetched_at, scan_verdict, forward_query, utm_source, utm_medium, utm_campaign, utm_term, utm_content, targeting_rules, geo_rules, blocked_countries, variants, sticky_variants, activate_at, schedule, schedule_timezone, enabled, deleted_at, normalized_link, alias_key, qr_scans, alias_key_unique
+`
+
+type SetLinkNotesParams struct {
+	ID    uuid.UUID      `json:"id"`
+	Notes sql.NullString `json:"notes"`
+}
+
+func (q *Queries) SetLinkNotes(ctx context.Context, arg SetLinkNotesParams) (Shortly, error) {
+	row := q.db.QueryRowContext(ctx, setLinkNotes, arg.ID, arg.Notes)
+	var i Shortly
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.ShortLink,
+		&i.LongLink,
+		&i.ClickCount,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ExpiresAt,
+		&i.MaxClicks,
+		&i.PasswordHash,
+		&i.RedirectType,
+		&i.DomainID,
+		&i.Folder,
+		&i.Notes,
+		&i.Title,
+		&i.Description,
+		&i.FaviconUrl,
+		&i.OgTitle,
+		&i.OgDescription,
+		&i.OgImage,
+		&i.MetadataFetchedAt,
+		&i.ScanVerdict,
+		&i.ForwardQuery,
+		&i.UtmSource,
+		&i.UtmMedium,
+		&i.UtmCampaign,
+		&i.UtmTerm,
+		&i.UtmContent,
+		&i.TargetingRules,
+		&i.GeoRules,
+		pq.Array(&i.BlockedCountries),
+		&i.Variants,
+		&i.StickyVariants,
+		&i.ActivateAt,
+		&i.Schedule,
+		&i.ScheduleTimezone,
+		&i.Enabled,
+		&i.DeletedAt,
+		&i.NormalizedLink,
+		&i.AliasKey,
+		&i.QrScans,
+		&i.AliasKeyUnique,
+	)
+	return i, err
+}
+
+const setLinkPassword = `-- name: SetLinkPassword :exec
+UPDATE shortly
+SET password_hash = $2, updated_at = NOW()
+WHERE id = $1
+`
+
+type SetLinkPasswordParams struct {
+	ID           uuid.UUID      `json:"id"`
+	PasswordHash sql.NullString `json:"password_hash"`
+}
+
+func (q *Queries) SetLinkPassword(ctx context.Context, arg SetLinkPasswordParams) error {
+	_, err := q.db.ExecContext(ctx, setLinkPassword, arg.ID, arg.PasswordHash)
+	return err
+}
+
+const setLinkQueryOptions = `-- name: SetLinkQueryOptions :one
+UPDATE shortly
+SET forward_query = $2,
+    utm_source = $3,
+    utm_medium = $4,
+    utm_campaign = $5,
+    utm_term = $6,
+    utm_content = $7,
+    updated_at = NOW()
+WHERE id = $1
+RETURNING id, user_id, short_link, long_link, click_count, created_at, updated_at, expires_at, max_clicks, password_hash, redirect_type, domain_id, folder, notes, title, description, favicon_url, og_title, og_description, og_image, metadata_fetched_at, scan_verdict, forward_query, utm_source, utm_medium, utm_campaign, utm_term, utm_content, targeting_rules, geo_rules, blocked_countries, variants, sticky_variants, activate_at, schedule, schedule_timezone, enabled, deleted_at, normalized_link, alias_key, qr_scans, alias_key_unique
+`
+
+type SetLinkQueryOptionsParams struct {
+	ID           uuid.UUID      `json:"id"`
+	ForwardQuery bool           `json:"forward_query"`
+	UtmSource    sql.NullString `json:"utm_source"`
+	UtmMedium    sql.NullString `json:"utm_medium"`
+	UtmCampaign  sql.NullString `json:"utm_campaign"`
+	UtmTerm      sql.NullString `json:"utm_term"`
+	UtmContent   sql.NullString `json:"utm_content"`
+}
+
+func (q *Queries) SetLinkQueryOptions(ctx context.Context, arg SetLinkQueryOptionsParams) (Shortly, error) {
+	row := q.db.QueryRowContext(ctx, setLinkQueryOptions,
+		arg.ID,
+		arg.ForwardQuery,
+		arg.UtmSource,
+		arg.UtmMedium,
+		arg.UtmCampaign,
+		arg.UtmTerm,
+		arg.UtmContent,
+	)
+	var i Shortly
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.ShortLink,
+		&i.LongLink,
+		&i.ClickCount,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ExpiresAt,
+		&i.MaxClicks,
+		&i.PasswordHash,
+		&i.RedirectType,
+		&i.DomainID,
+		&i.Folder,
+		&i.Notes,
+		&i.Title,
+		&i.Description,
+		&i.FaviconUrl,
+		&i.OgTitle,
+		&i.OgDescription,
+		&i.OgImage,
+		&i.MetadataFetchedAt,
+		&i.ScanVerdict,
+		&i.ForwardQuery,
+		&i.UtmSource,
+		&i.UtmMedium,
+		&i.UtmCampaign,
+		&i.UtmTerm,
+		&i.UtmContent,
+		&i.TargetingRules,
+		&i.GeoRules,
+		pq.Array(&i.BlockedCountries),
+		&i.Variants,
+		&i.StickyVariants,
+		&i.ActivateAt,
+		&i.Schedule,
+		&i.ScheduleTimezone,
+		&i.Enabled,
+		&i.DeletedAt,
+		&i.NormalizedLink,
+		&i.AliasKey,
+		&i.QrScans,
+		&i.AliasKeyUnique,
+	)
+	return i, err
+}
+
+const setLinkSchedule = `-- name: SetLinkSchedule :one
+UPDATE shortly
+SET schedule = $2,
+    schedule_timezone = $3,
+    updated_at = NOW()
+WHERE id = $1
+RETURNING id, user_id, short_link, long_link, click_count, created_at, updated_at, expires_at, max_clicks, password_hash, redirect_type, domain_id, folder, notes, title, description, favicon_url, og_title, og_description, og_image, metadata_fetched_at, scan_verdict, forward_query, utm_source, utm_medium, utm_campaign, utm_term, utm_content, targeting_rules, geo_rules, blocked_countries, variants, sticky_variants, activate_at, schedule, schedule_timezone, enabled, deleted_at, normalized_link, alias_key, qr_scans, alias_key_unique
+`
+
+type SetLinkScheduleParams struct {
+	ID               uuid.UUID       `json:"id"`
+	Schedule         json.RawMessage `json:"schedule"`
+	ScheduleTimezone string          `json:"schedule_timezone"`
+}
+
+func (q *Queries) SetLinkSchedule(ctx context.Context, arg SetLinkScheduleParams) (Shortly, error) {
+	row := q.db.QueryRowContext(ctx, setLinkSchedule, arg.ID, arg.Schedule, arg.ScheduleTimezone)
+	var i Shortly
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.ShortLink,
+		&i.LongLink,
+		&i.ClickCount,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ExpiresAt,
+		&i.MaxClicks,
+		&i.PasswordHash,
+		&i.RedirectType,
+		&i.DomainID,
+		&i.Folder,
+		&i.Notes,
+		&i.Title,
+		&i.Description,
+		&i.FaviconUrl,
+		&i.OgTitle,
+		&i.OgDescription,
+		&i.OgImage,
+		&i.MetadataFetchedAt,
+		&i.ScanVerdict,
+		&i.ForwardQuery,
+		&i.UtmSource,
+		&i.UtmMedium,
+		&i.UtmCampaign,
+		&i.UtmTerm,
+		&i.UtmContent,
+		&i.TargetingRules,
+		&i.GeoRules,
+		pq.Array(&i.BlockedCountries),
+		&i.Variants,
+		&i.StickyVariants,
+		&i.ActivateAt,
+		&i.Schedule,
+		&i.ScheduleTimezone,
+		&i.Enabled,
+		&i.DeletedAt,
+		&i.NormalizedLink,
+		&i.AliasKey,
+		&i.QrScans,
+		&i.AliasKeyUnique,
+	)
+	return i, err
+}
+
+const setLinkTargetingRules = `-- name: SetLinkTargetingRules :one
+UPDATE shortly
+SET targeting_rules = $2,
+    updated_at = NOW()
+WHERE id = $1
+RETURNING id, user_id, short_link, long_link, click_count, created_at, updated_at, expires_at, max_clicks, password_hash, redirect_type, domain_id, folder, notes, title, description, favicon_url, og_title, og_description, og_image, metadata_fetched_at, scan_verdict, forward_query, utm_source, utm_medium, utm_campaign, utm_term, utm_content, targeting_rules, geo_rules, blocked_countries, variants, sticky_variants, activate_at, schedule, schedule_timezone, enabled, deleted_at, normalized_link, alias_key, qr_scans, alias_key_unique
+`
+
+type SetLinkTargetingRulesParams struct {
+	ID             uuid.UUID       `json:"id"`
+	TargetingRules json.RawMessage `json:"targeting_rules"`
+}
+
+func (q *Queries) SetLinkTargetingRules(ctx context.Context, arg SetLinkTargetingRulesParams) (Shortly, error) {
+	row := q.db.QueryRowContext(ctx, setLinkTargetingRules, arg.ID, arg.TargetingRules)
+	var i Shortly
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.ShortLink,
+		&i.LongLink,
+		&i.ClickCount,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ExpiresAt,
+		&i.MaxClicks,
+		&i.PasswordHash,
+		&i.RedirectType,
+		&i.DomainID,
+		&i.Folder,
+		&i.Notes,
+		&i.Title,
+		&i.Description,
+		&i.FaviconUrl,
+		&i.OgTitle,
+		&i.OgDescription,
+		&i.OgImage,
+		&i.MetadataFetchedAt,
+		&i.ScanVerdict,
+		&i.ForwardQuery,
+		&i.UtmSource,
+		&i.UtmMedium,
+		&i.UtmCampaign,
+		&i.UtmTerm,
+		&i.UtmContent,
+		&i.TargetingRules,
+		&i.GeoRules,
+		pq.Array(&i.BlockedCountries),
+		&i.Variants,
+		&i.StickyVariants,
+		&i.ActivateAt,
+		&i.Schedule,
+		&i.ScheduleTimezone,
+		&i.Enabled,
+		&i.DeletedAt,
+		&i.NormalizedLink,
+		&i.AliasKey,
+		&i.QrScans,
+		&i.AliasKeyUnique,
+	)
+	return i, err
+}
+
+const trashLink = `-- name: TrashLink :one
+UPDATE shortly
+SET deleted_at = NOW(), updated_at = NOW()
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING id, user_id, short_link, long_link, click_count, created_at, updated_at, expires_at, max_clicks, password_hash, redirect_type, domain_id, folder, notes, title, description, favicon_url, og_title, og_description, og_image, metadata_fetched_at, scan_verdict, forward_query, utm_source, utm_medium, utm_campaign, utm_term, utm_content, targeting_rules, geo_rules, blocked_countries, variants, sticky_variants, activate_at, schedule, schedule_timezone, enabled, deleted_at, normalized_link, alias_key, qr_scans, alias_key_unique
+`
+
+func (q *Queries) TrashLink(ctx context.Context, id uuid.UUID) (Shortly, error) {
+	row := q.db.QueryRowContext(ctx, trashLink, id)
+	var i Shortly
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.ShortLink,
+		&i.LongLink,
+		&i.ClickCount,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ExpiresAt,
+		&i.MaxClicks,
+		&i.PasswordHash,
+		&i.RedirectType,
+		&i.DomainID,
+		&i.Folder,
+		&i.Notes,
+		&i.Title,
+		&i.Description,
+		&i.FaviconUrl,
+		&i.OgTitle,
+		&i.OgDescription,
+		&i.OgImage,
+		&i.MetadataFetchedAt,
+		&i.ScanVerdict,
+		&i.ForwardQuery,
+		&i.UtmSource,
+		&i.UtmMedium,
+		&i.UtmCampaign,
+		&i.UtmTerm,
+		&i.UtmContent,
+		&i.TargetingRules,
+		&i.GeoRules,
+		pq.Array(&i.BlockedCountries),
+		&i.Variants,
+		&i.StickyVariants,
+		&i.ActivateAt,
+		&i.Schedule,
+		&i.ScheduleTimezone,
+		&i.Enabled,
+		&i.DeletedAt,
+		&i.NormalizedLink,
+		&i.AliasKey,
+		&i.QrScans,
+		&i.AliasKeyUnique,
+	)
+	return i, err
+}
+
+const updateLink = `-- name: UpdateLink :one
+UPDATE shortly
+SET short_link = $2, long_link = $3, redirect_type = $4, scan_verdict = $5, normalized_link = $6, alias_key = $7, alias_key_unique = $8,
+    updated_at = NOW()
+WHERE id = $1
+RETURNING id, user_id, short_link, long_link, click_count, created_at, updated_at, expires_at, max_clicks, password_hash, redirect_type, domain_id, folder, notes, title, description, favicon_url, og_title, og_description, og_image, metadata_fetched_at, scan_verdict, forward_query, utm_source, utm_medium, utm_campaign, utm_term, utm_content, targeting_rules, geo_rules, blocked_countries, variants, sticky_variants, activate_at, schedule, schedule_timezone, enabled, deleted_at, normalized_link, alias_key, qr_scans, alias_key_unique
+`
+
+type UpdateLinkParams struct {
+	ID             uuid.UUID      `json:"id"`
+	ShortLink      string         `json:"short_link"`
+	LongLink       string         `json:"long_link"`
+	RedirectType   sql.NullInt32  `json:"redirect_type"`
+	ScanVerdict    sql.NullString `json:"scan_verdict"`
+	NormalizedLink sql.NullString `json:"normalized_link"`
+	AliasKey       string         `json:"alias_key"`
+	AliasKeyUnique bool           `json:"alias_key_unique"`
+}
+
+func (q *Queries) UpdateLink(ctx context.Context, arg UpdateLinkParams) (Shortly, error) {
+	row := q.db.QueryRowContext(ctx, updateLink,
+		arg.ID,
+		arg.ShortLink,
+		arg.LongLink,
+		arg.RedirectType,
+		arg.ScanVerdict,
+		arg.NormalizedLink,
+		arg.AliasKey,
+		arg.AliasKeyUnique,
+	)
+	var i Shortly
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.ShortLink,
+		&i.LongLink,
+		&i.ClickCount,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ExpiresAt,
+		&i.MaxClicks,
+		&i.PasswordHash,
+		&i.RedirectType,
+		&i.DomainID,
+		&i.Folder,
+		&i.Notes,
+		&i.Title,
+		&i.Description,
+		&i.FaviconUrl,
+		&i.OgTitle,
+		&i.OgDescription,
+		&i.OgImage,
+		&i.MetadataFetchedAt,
+		&i.ScanVerdict,
+		&i.ForwardQuery,
+		&i.UtmSource,
+		&i.UtmMedium,
+		&i.UtmCampaign,
+		&i.UtmTerm,
+		&i.UtmContent,
+		&i.TargetingRules,
+		&i.GeoRules,
+		pq.Array(&i.BlockedCountries),
+		&i.Variants,
+		&i.StickyVariants,
+		&i.ActivateAt,
+		&i.Schedule,
+		&i.ScheduleTimezone,
+		&i.Enabled,
+		&i.DeletedAt,
+		&i.NormalizedLink,
+		&i.AliasKey,
+		&i.QrScans,
+		&i.AliasKeyUnique,
+	)
+	return i, err
 }
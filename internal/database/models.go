@@ -5,19 +5,108 @@
 package database
 
 import (
+	"database/sql"
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+type Domain struct {
+	ID                uuid.UUID    `json:"id"`
+	UserID            uuid.UUID    `json:"user_id"`
+	Host              string       `json:"host"`
+	CreatedAt         time.Time    `json:"created_at"`
+	VerificationToken string       `json:"verification_token"`
+	VerifiedAt        sql.NullTime `json:"verified_at"`
+}
+
+type IdempotencyKey struct {
+	UserID       uuid.UUID     `json:"user_id"`
+	Key          string        `json:"key"`
+	RequestHash  string        `json:"request_hash"`
+	StatusCode   sql.NullInt32 `json:"status_code"`
+	ResponseBody []byte        `json:"response_body"`
+	CreatedAt    time.Time     `json:"created_at"`
+}
+
+type LinkRevision struct {
+	ID           uuid.UUID     `json:"id"`
+	LinkID       uuid.UUID     `json:"link_id"`
+	Revision     int32         `json:"revision"`
+	ShortLink    string        `json:"short_link"`
+	LongLink     string        `json:"long_link"`
+	RedirectType sql.NullInt32 `json:"redirect_type"`
+	CreatedAt    time.Time     `json:"created_at"`
+}
+
+type LinkTag struct {
+	LinkID uuid.UUID `json:"link_id"`
+	TagID  uuid.UUID `json:"tag_id"`
+}
+
 type Shortly struct {
-	ID         uuid.UUID `json:"id"`
-	UserID     uuid.UUID `json:"user_id"`
-	ShortLink  string    `json:"short_link"`
-	LongLink   string    `json:"long_link"`
-	ClickCount int32     `json:"click_count"`
-	CreatedAt  time.Time `json:"created_at"`
-	UpdatedAt  time.Time `json:"updated_at"`
+	ID                uuid.UUID       `json:"id"`
+	UserID            uuid.UUID       `json:"user_id"`
+	ShortLink         string          `json:"short_link"`
+	LongLink          string          `json:"long_link"`
+	ClickCount        int32           `json:"click_count"`
+	CreatedAt         time.Time       `json:"created_at"`
+	UpdatedAt         time.Time       `json:"updated_at"`
+	ExpiresAt         sql.NullTime    `json:"expires_at"`
+	MaxClicks         sql.NullInt32   `json:"max_clicks"`
+	PasswordHash      sql.NullString  `json:"-"`
+	RedirectType      sql.NullInt32   `json:"redirect_type"`
+	DomainID          uuid.NullUUID   `json:"domain_id"`
+	Folder            sql.NullString  `json:"folder"`
+	Notes             sql.NullString  `json:"notes"`
+	Title             sql.NullString  `json:"title"`
+	Description       sql.NullString  `json:"description"`
+	FaviconUrl        sql.NullString  `json:"favicon_url"`
+	OgTitle           sql.NullString  `json:"og_title"`
+	OgDescription     sql.NullString  `json:"og_description"`
+	OgImage           sql.NullString  `json:"og_image"`
+	MetadataFetchedAt sql.NullTime    `json:"metadata_fetched_at"`
+	ScanVerdict       sql.NullString  `json:"scan_verdict"`
+	ForwardQuery      bool            `json:"forward_query"`
+	UtmSource         sql.NullString  `json:"utm_source"`
+	UtmMedium         sql.NullString  `json:"utm_medium"`
+	UtmCampaign       sql.NullString  `json:"utm_campaign"`
+	UtmTerm           sql.NullString  `json:"utm_term"`
+	UtmContent        sql.NullString  `json:"utm_content"`
+	TargetingRules    json.RawMessage `json:"targeting_rules"`
+	GeoRules          json.RawMessage `json:"geo_rules"`
+	BlockedCountries  []string        `json:"blocked_countries"`
+	Variants          json.RawMessage `json:"variants"`
+	StickyVariants    bool            `json:"sticky_variants"`
+	ActivateAt        sql.NullTime    `json:"activate_at"`
+	Schedule          json.RawMessage `json:"schedule"`
+	ScheduleTimezone  string          `json:"schedule_timezone"`
+	Enabled           bool            `json:"enabled"`
+	DeletedAt         sql.NullTime    `json:"deleted_at"`
+	NormalizedLink    sql.NullString  `json:"normalized_link"`
+	AliasKey          string          `json:"alias_key"`
+	QrScans           int32           `json:"qr_scans"`
+	AliasKeyUnique    bool            `json:"alias_key_unique"`
+}
+
+type ShortlyArchive struct {
+	ID         uuid.UUID     `json:"id"`
+	UserID     uuid.UUID     `json:"user_id"`
+	ShortLink  string        `json:"short_link"`
+	LongLink   string        `json:"long_link"`
+	ClickCount int32         `json:"click_count"`
+	CreatedAt  time.Time     `json:"created_at"`
+	ExpiresAt  time.Time     `json:"expires_at"`
+	ArchivedAt time.Time     `json:"archived_at"`
+	DomainID   uuid.NullUUID `json:"domain_id"`
+}
+
+type Tag struct {
+	ID        uuid.UUID `json:"id"`
+	UserID    uuid.UUID `json:"user_id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
 }
 
 type User struct {
@@ -28,3 +117,9 @@ type User struct {
 	CreatedAt    time.Time `json:"created_at"`
 	UpdatedAt    time.Time `json:"updated_at"`
 }
+
+type VariantClick struct {
+	LinkID  uuid.UUID `json:"link_id"`
+	Variant string    `json:"variant"`
+	Clicks  int64     `json:"clicks"`
+}
@@ -12,10 +12,16 @@ import (
 	"github.com/gofiber/swagger" // swagger handler
 	"github.com/redis/go-redis/v9"
 
+	"github.com/tin3ga/shortly/alias"
 	"github.com/tin3ga/shortly/cache"
 	"github.com/tin3ga/shortly/config"
 	"github.com/tin3ga/shortly/db"
+	"github.com/tin3ga/shortly/geoip"
+	"github.com/tin3ga/shortly/handler"
+	"github.com/tin3ga/shortly/middleware"
+	"github.com/tin3ga/shortly/qr"
 	"github.com/tin3ga/shortly/router"
+	"github.com/tin3ga/shortly/worker"
 
 	"github.com/tin3ga/shortly/internal/database"
 
@@ -56,7 +62,8 @@ func main() {
 	var rdb *redis.Client
 
 	if cfg.EnableCaching {
-		rdb, err := cache.InitializeRedis(ctx, cfg.RedisAddr, cfg.RedisPassword, cfg.RedisDB)
+		var err error
+		rdb, err = cache.InitializeRedis(ctx, cfg.RedisAddr, cfg.RedisPassword, cfg.RedisDB)
 
 		if err != nil {
 			log.Fatalf("Failed to initialize Redis: %v", err)
@@ -68,15 +75,100 @@ func main() {
 
 	// end Redis setup
 
-	app := fiber.New()
+	// GeoIP database for geo targeted links
+
+	var geo *geoip.Reader
+
+	if cfg.GeoIPDatabase != "" {
+		geo, err = geoip.Open(cfg.GeoIPDatabase)
+		if err != nil {
+			log.Fatalf("Failed to open GeoIP database: %v", err)
+		}
+		defer geo.Close()
+
+		log.Printf("GeoIP Enabled: %v", cfg.GeoIPDatabase)
+		log.Printf("--Trusted Proxies: %v", cfg.TrustedProxies)
+	}
+
+	// Custom aliases must follow the alias policy, route names are reserved once the routes are set up
+
+	policy, err := alias.NewPolicy(cfg.AliasMinLength, cfg.AliasMaxLength, cfg.ReservedAliasesFile)
+	if err != nil {
+		log.Fatalf("Invalid alias policy: %v", err)
+	}
+	policy.CaseInsensitive = cfg.CaseInsensitiveAliases
+	log.Printf("Case Insensitive Aliases: %v", policy.CaseInsensitive)
+
+	if count, err := worker.BackfillAliasKeys(ctx, queries); err != nil {
+		log.Fatalf("Failed to backfill alias keys: %v", err)
+	} else if count > 0 {
+		log.Printf("--Alias Keys Backfilled: %v", count)
+	}
+
+	// Aliases of links created without a custom alias
+
+	aliases, err := alias.New(alias.Options{
+		Strategy: cfg.AliasStrategy,
+		Length:   cfg.AliasLength,
+		Words:    cfg.AliasWords,
+		Salt:     cfg.AliasSalt,
+	}, queries, policy)
+	if err != nil {
+		log.Fatalf("Invalid alias configuration: %v", err)
+	}
+	log.Printf("Alias Strategy: %v", aliases.Strategy())
+
+	// Logo QR codes can show in their centre
+
+	var logo *qr.Logo
+
+	if cfg.QRLogoFile != "" {
+		logo, err = qr.LoadLogo(cfg.QRLogoFile)
+		if err != nil {
+			log.Fatalf("Failed to load QR code logo: %v", err)
+		}
+
+		log.Printf("QR Code Logo: %v", cfg.QRLogoFile)
+	}
+
+	// Expired links reaper
+
+	if cfg.ReaperInterval > 0 {
+		worker.StartLinkReaper(ctx, queries, cfg.ReaperInterval, cfg.ReaperMode)
+
+		log.Printf("Link Reaper Enabled: %v", cfg.ReaperInterval > 0)
+		log.Printf("--Reaper Interval: %v", cfg.ReaperInterval)
+		log.Printf("--Reaper Mode: %v", cfg.ReaperMode)
+	}
+
+	// behind a load balancer the client ip is read from X-Forwarded-For by handler.ClientIP, but only on requests
+	// from a trusted proxy
+	app := fiber.New(fiber.Config{
+		EnableTrustedProxyCheck: len(cfg.TrustedProxies) > 0,
+		TrustedProxies:          cfg.TrustedProxies,
+		EnableIPValidation:      true,
+	})
 
 	// Enable CORS
 	app.Use(cors.New(cors.Config{
 		AllowOrigins: "*",
-		AllowMethods: "GET,POST,PUT,DELETE",
+		AllowMethods: "GET,POST,PUT,PATCH,DELETE",
 		AllowHeaders: "Origin, Content-Type, Accept",
 	}))
 
+	// Background purgers
+
+	// Deleted links stay in the trash for the retention period
+	trashRetention := cfg.TrashRetention
+	if trashRetention <= 0 {
+		trashRetention = worker.DefaultTrashRetention
+	}
+	worker.StartTrashPurger(ctx, queries, trashRetention)
+	log.Printf("Trash Retention: %v", trashRetention)
+
+	// Responses stored for an Idempotency-Key are replayed for a day
+	worker.StartIdempotencyKeyPurger(ctx, queries, middleware.IdempotencyKeyTTL)
+
 	// Rate limiter
 
 	// Set up in-memory store for the rate limiter
@@ -90,6 +182,7 @@ func main() {
 			},
 			SkipFailedRequests:     cfg.SkipFailedRequests,
 			SkipSuccessfulRequests: cfg.SkipSuccessfulRequests,
+			KeyGenerator:           handler.ClientIP,
 		}
 		app.Use(limiter.New(limiterCfg))
 
@@ -119,7 +212,13 @@ func main() {
 
 	app.Get("/swagger/*", swagger.HandlerDefault) // default
 
-	router.SetupRoutes(app, queries, ctx, rdb, cfg.CacheTTL, cfg.APIKey, cfg.JWTSecret)
+	router.SetupRoutes(app, db, queries, ctx, rdb, geo, aliases, policy, logo, cfg)
+
+	var paths []string
+	for _, route := range app.GetRoutes(true) {
+		paths = append(paths, route.Path)
+	}
+	policy.ReservePaths(paths)
 
 	app.Listen(":" + cfg.Port)
 }
@@ -0,0 +1,94 @@
+package metadata
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+)
+
+const (
+	DefaultTimeout  = 5 * time.Second
+	DefaultMaxBytes = 1 << 20 // 1 MiB
+
+	userAgent = "Mozilla/5.0 (compatible; ShortlyBot/1.0)"
+)
+
+var errPrivateAddress = errors.New("refusing to fetch a private address")
+
+// HTTPFetcher fetches pages over HTTP, only the first MaxBytes of a page are read
+type HTTPFetcher struct {
+	Client   *http.Client
+	MaxBytes int64
+}
+
+// NewHTTPFetcher returns a fetcher that gives up after timeout and never connects to private addresses.
+// Zero values use DefaultTimeout and DefaultMaxBytes.
+func NewHTTPFetcher(timeout time.Duration, maxBytes int64) *HTTPFetcher {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	if maxBytes <= 0 {
+		maxBytes = DefaultMaxBytes
+	}
+
+	dialer := &net.Dialer{Timeout: timeout, Control: denyPrivateAddresses}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &HTTPFetcher{
+		Client: &http.Client{
+			Timeout:   timeout,
+			Transport: transport,
+		},
+		MaxBytes: maxBytes,
+	}
+}
+
+// denyPrivateAddresses stops the fetcher from being used to reach internal services, redirects included
+func denyPrivateAddresses(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsMulticast() {
+		return errPrivateAddress
+	}
+	return nil
+}
+
+// Fetch downloads the page at link and parses its metadata, non HTML responses are an error
+func (f *HTTPFetcher) Fetch(ctx context.Context, link string) (Metadata, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, link, nil)
+	if err != nil {
+		return Metadata{}, err
+	}
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set("Accept", "text/html,application/xhtml+xml")
+
+	resp, err := f.Client.Do(req)
+	if err != nil {
+		return Metadata{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return Metadata{}, fmt.Errorf("unexpected status %v", resp.Status)
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if mediaType != "text/html" && mediaType != "application/xhtml+xml" {
+		return Metadata{}, fmt.Errorf("unexpected content type %q", mediaType)
+	}
+
+	// resolve relative urls against the final url after redirects
+	return Parse(io.LimitReader(resp.Body, f.MaxBytes), resp.Request.URL)
+}
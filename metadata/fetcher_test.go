@@ -0,0 +1,154 @@
+package metadata
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestFetcher returns a fetcher with a plain client, NewHTTPFetcher refuses to reach httptest servers on loopback
+func newTestFetcher(timeout time.Duration, maxBytes int64) *HTTPFetcher {
+	return &HTTPFetcher{Client: &http.Client{Timeout: timeout}, MaxBytes: maxBytes}
+}
+
+func TestHTTPFetcherFetch(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/old", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/articles/new", http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("/articles/new", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("User-Agent") != userAgent {
+			t.Errorf("User-Agent = %q", r.Header.Get("User-Agent"))
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(`<head><title>New</title><link rel="icon" href="icon.svg"><meta property="og:image" content="../cover.jpg"></head>`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	got, err := newTestFetcher(time.Second, DefaultMaxBytes).Fetch(context.Background(), server.URL+"/old")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// relative urls resolve against the page after the redirect
+	want := Metadata{Title: "New", Favicon: server.URL + "/articles/icon.svg", OGImage: server.URL + "/cover.jpg"}
+	if got != want {
+		t.Errorf("Fetch() = %+v, want %+v", got, want)
+	}
+}
+
+func TestHTTPFetcherRejectsNonHTML(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/pdf")
+		w.Write([]byte("%PDF-1.7"))
+	}))
+	defer server.Close()
+
+	_, err := newTestFetcher(time.Second, DefaultMaxBytes).Fetch(context.Background(), server.URL)
+	if err == nil || !strings.Contains(err.Error(), "content type") {
+		t.Errorf("Fetch() error = %v, want a content type error", err)
+	}
+}
+
+func TestHTTPFetcherRejectsErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	if _, err := newTestFetcher(time.Second, DefaultMaxBytes).Fetch(context.Background(), server.URL); err == nil {
+		t.Error("Fetch() of a 404 page succeeded")
+	}
+}
+
+func TestHTTPFetcherReadsAtMostMaxBytes(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(`<head><meta name="description" content="early">` + strings.Repeat("<!-- padding -->", 100) + `<title>late</title></head>`))
+	}))
+	defer server.Close()
+
+	got, err := newTestFetcher(time.Second, 100).Fetch(context.Background(), server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Description != "early" || got.Title != "" {
+		t.Errorf("Fetch() = %+v, want only the description before the limit", got)
+	}
+}
+
+func TestHTTPFetcherTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	start := time.Now()
+	_, err := newTestFetcher(50*time.Millisecond, DefaultMaxBytes).Fetch(context.Background(), server.URL)
+	if err == nil {
+		t.Fatal("Fetch() of a hanging server succeeded")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Fetch() gave up after %v", elapsed)
+	}
+}
+
+func TestNewHTTPFetcherRefusesLoopback(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("the fetcher reached a loopback server")
+	}))
+	defer server.Close()
+
+	_, err := NewHTTPFetcher(time.Second, 0).Fetch(context.Background(), server.URL)
+	if !errors.Is(err, errPrivateAddress) {
+		t.Errorf("Fetch() error = %v, want %v", err, errPrivateAddress)
+	}
+}
+
+func TestNewHTTPFetcherRefusesHostsResolvingToLoopback(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("the fetcher reached a loopback server")
+	}))
+	defer server.Close()
+
+	// the guard checks the address that is dialled, not the host name of the url
+	link := strings.Replace(server.URL, "127.0.0.1", "localhost", 1)
+	_, err := NewHTTPFetcher(time.Second, 0).Fetch(context.Background(), link)
+	if !errors.Is(err, errPrivateAddress) {
+		t.Errorf("Fetch(%v) error = %v, want %v", link, err, errPrivateAddress)
+	}
+}
+
+func TestDenyPrivateAddresses(t *testing.T) {
+	tests := []struct {
+		address string
+		denied  bool
+	}{
+		{"127.0.0.1:80", true},
+		{"[::1]:80", true},
+		{"10.0.0.8:80", true},
+		{"172.16.4.2:443", true},
+		{"192.168.1.1:80", true},
+		{"169.254.169.254:80", true},
+		{"0.0.0.0:80", true},
+		{"[fd00::1]:80", true},
+		{"[fe80::1]:80", true},
+		{"93.184.216.34:443", false},
+		{"[2606:2800:220:1:248:1893:25c8:1946]:443", false},
+	}
+
+	for _, tt := range tests {
+		err := denyPrivateAddresses("tcp", tt.address, nil)
+		if denied := errors.Is(err, errPrivateAddress); denied != tt.denied {
+			t.Errorf("denyPrivateAddresses(%v) = %v, want denied %v", tt.address, err, tt.denied)
+		}
+	}
+}
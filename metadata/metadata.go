@@ -0,0 +1,175 @@
+package metadata
+
+import (
+	"context"
+	"io"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+)
+
+const (
+	maxTextLength = 1000
+	maxURLLength  = 2048
+)
+
+// Metadata is the information shown for a link, empty fields were not found on the page
+type Metadata struct {
+	Title         string
+	Description   string
+	Favicon       string
+	OGTitle       string
+	OGDescription string
+	OGImage       string
+}
+
+// Fetcher loads the metadata of the page at a url
+type Fetcher interface {
+	Fetch(ctx context.Context, link string) (Metadata, error)
+}
+
+// Parse reads the <head> of an HTML document, relative favicon and image urls are resolved against base
+func Parse(r io.Reader, base *url.URL) (Metadata, error) {
+	var meta Metadata
+	var inTitle bool
+	var title strings.Builder
+
+	tokenizer := html.NewTokenizer(r)
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			if tokenizer.Err() == io.EOF {
+				return meta.finish(title.String(), base), nil
+			}
+			return meta.finish(title.String(), base), tokenizer.Err()
+
+		case html.TextToken:
+			if inTitle {
+				title.Write(tokenizer.Text())
+			}
+
+		case html.EndTagToken:
+			name, _ := tokenizer.TagName()
+			switch string(name) {
+			case "title":
+				inTitle = false
+			case "head":
+				return meta.finish(title.String(), base), nil
+			}
+
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, hasAttr := tokenizer.TagName()
+			switch string(name) {
+			case "title":
+				inTitle = title.Len() == 0
+			case "body":
+				return meta.finish(title.String(), base), nil
+			case "meta":
+				if hasAttr {
+					meta.readMeta(attributes(tokenizer))
+				}
+			case "link":
+				if hasAttr {
+					meta.readLink(attributes(tokenizer))
+				}
+			}
+		}
+	}
+}
+
+func attributes(tokenizer *html.Tokenizer) map[string]string {
+	attrs := make(map[string]string)
+	for {
+		key, val, more := tokenizer.TagAttr()
+		attrs[string(key)] = string(val)
+		if !more {
+			return attrs
+		}
+	}
+}
+
+func (m *Metadata) readMeta(attrs map[string]string) {
+	content := attrs["content"]
+	if content == "" {
+		return
+	}
+
+	// Open Graph tags use property, but name is common enough to accept as well
+	key := strings.ToLower(attrs["property"])
+	if key == "" {
+		key = strings.ToLower(attrs["name"])
+	}
+
+	switch key {
+	case "description":
+		setOnce(&m.Description, content)
+	case "og:title":
+		setOnce(&m.OGTitle, content)
+	case "og:description":
+		setOnce(&m.OGDescription, content)
+	case "og:image", "og:image:url":
+		setOnce(&m.OGImage, content)
+	}
+}
+
+func (m *Metadata) readLink(attrs map[string]string) {
+	for _, rel := range strings.Fields(strings.ToLower(attrs["rel"])) {
+		if rel == "icon" {
+			// prefer the first declared icon, "shortcut icon" and "icon" are both matched
+			setOnce(&m.Favicon, attrs["href"])
+			return
+		}
+	}
+}
+
+func setOnce(field *string, value string) {
+	if *field == "" {
+		*field = value
+	}
+}
+
+// finish cleans up the collected values and resolves urls, sites without an icon link fall back to /favicon.ico
+func (m Metadata) finish(title string, base *url.URL) Metadata {
+	m.Title = clean(title)
+	m.Description = clean(m.Description)
+	m.OGTitle = clean(m.OGTitle)
+	m.OGDescription = clean(m.OGDescription)
+
+	if m.Favicon == "" {
+		m.Favicon = "/favicon.ico"
+	}
+	m.Favicon = resolve(base, m.Favicon)
+	m.OGImage = resolve(base, m.OGImage)
+
+	return m
+}
+
+// clean collapses whitespace and truncates long text
+func clean(text string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if utf8.RuneCountInString(text) > maxTextLength {
+		text = string([]rune(text)[:maxTextLength])
+	}
+	return text
+}
+
+// resolve makes ref absolute, anything that is not an http(s) url is dropped
+func resolve(base *url.URL, ref string) string {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return ""
+	}
+	u, err := url.Parse(ref)
+	if err != nil {
+		return ""
+	}
+	if base != nil {
+		u = base.ResolveReference(u)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || len(u.String()) > maxURLLength {
+		return ""
+	}
+	return u.String()
+}
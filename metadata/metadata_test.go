@@ -0,0 +1,78 @@
+package metadata
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	base, _ := url.Parse("https://example.com/blog/post")
+
+	tests := []struct {
+		name string
+		page string
+		want Metadata
+	}{
+		{
+			name: "all fields",
+			page: `<html><head>
+				<title>  Hello
+				World </title>
+				<meta name="description" content="A post">
+				<meta property="og:title" content="OG Hello">
+				<meta property="og:description" content="OG post">
+				<meta property="og:image" content="/images/cover.png">
+				<link rel="shortcut icon" href="icon.png">
+				</head><body><title>ignored</title></body></html>`,
+			want: Metadata{
+				Title:         "Hello World",
+				Description:   "A post",
+				Favicon:       "https://example.com/blog/icon.png",
+				OGTitle:       "OG Hello",
+				OGDescription: "OG post",
+				OGImage:       "https://example.com/images/cover.png",
+			},
+		},
+		{
+			name: "first value wins and name is accepted for open graph",
+			page: `<head><meta name="og:title" content="First"><meta property="og:title" content="Second">
+				<link rel="icon" href="//cdn.example.net/a.ico"><link rel="icon" href="/b.ico"></head>`,
+			want: Metadata{OGTitle: "First", Favicon: "https://cdn.example.net/a.ico"},
+		},
+		{
+			name: "default favicon",
+			page: `<head><title>No icon</title></head>`,
+			want: Metadata{Title: "No icon", Favicon: "https://example.com/favicon.ico"},
+		},
+		{
+			name: "non http urls are dropped",
+			page: `<head><link rel="icon" href="data:image/png;base64,AAAA"><meta property="og:image" content="javascript:alert(1)"></head>`,
+			want: Metadata{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(strings.NewReader(tt.page), base)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("Parse() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTruncatesLongText(t *testing.T) {
+	page := "<head><title>" + strings.Repeat("a", maxTextLength+10) + "</title></head>"
+
+	got, err := Parse(strings.NewReader(page), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Title) != maxTextLength {
+		t.Errorf("title has %d characters, want %d", len(got.Title), maxTextLength)
+	}
+}
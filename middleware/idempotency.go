@@ -0,0 +1,137 @@
+package middleware
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+
+	"github.com/tin3ga/shortly/internal/database"
+)
+
+const (
+	HeaderIdempotencyKey     = "Idempotency-Key"
+	HeaderIdempotentReplayed = "Idempotent-Replayed"
+
+	// IdempotencyKeyTTL is how long the response to a key is replayed
+	IdempotencyKeyTTL = 24 * time.Hour
+
+	maxIdempotencyKeyLength = 255
+)
+
+// Idempotency replays the stored response when a user repeats a request with the same Idempotency-Key header.
+// It must run after Protected, keys are scoped to the authenticated user. Server errors are not stored so the
+// request can be retried with the same key.
+func Idempotency(queries *database.Queries, ctx context.Context) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		key := c.Get(HeaderIdempotencyKey)
+		if key == "" {
+			return c.Next()
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Idempotency-Key is too long", "max_length": maxIdempotencyKeyLength})
+		}
+
+		userID, err := tokenUserID(c)
+		if err != nil {
+			log.Printf("Error parsing UserID: %v", err)
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"success": false, "message": "Invalid UserID format"})
+		}
+
+		hash := requestHash(c)
+		_, err = queries.ClaimIdempotencyKey(ctx, database.ClaimIdempotencyKeyParams{
+			UserID:      userID,
+			Key:         key,
+			RequestHash: hash,
+			TtlSeconds:  int64(IdempotencyKeyTTL.Seconds()),
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			return replay(c, queries, ctx, userID, key, hash)
+		}
+		if err != nil {
+			log.Print(err)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Internal server error"})
+		}
+
+		if err := c.Next(); err != nil {
+			release(queries, ctx, userID, key)
+			return err
+		}
+
+		status := c.Response().StatusCode()
+		if status >= fiber.StatusInternalServerError {
+			release(queries, ctx, userID, key)
+			return nil
+		}
+
+		params := database.SaveIdempotentResponseParams{
+			UserID:       userID,
+			Key:          key,
+			StatusCode:   sql.NullInt32{Int32: int32(status), Valid: true},
+			ResponseBody: append([]byte(nil), c.Response().Body()...),
+		}
+		if err := queries.SaveIdempotentResponse(ctx, params); err != nil {
+			log.Print(err)
+		}
+		return nil
+	}
+}
+
+// replay answers a request whose key was used before, with the stored response once the first request finished
+func replay(c *fiber.Ctx, queries *database.Queries, ctx context.Context, userID uuid.UUID, key, hash string) error {
+	stored, err := queries.GetIdempotencyKey(ctx, database.GetIdempotencyKeyParams{UserID: userID, Key: key})
+	if err != nil {
+		log.Print(err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Internal server error"})
+	}
+
+	if stored.RequestHash != hash {
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{"error": "Idempotency-Key was already used for a different request"})
+	}
+	if !stored.StatusCode.Valid {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "A request with this Idempotency-Key is still in progress"})
+	}
+
+	c.Set(HeaderIdempotentReplayed, "true")
+	c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	return c.Status(int(stored.StatusCode.Int32)).Send(stored.ResponseBody)
+}
+
+// release forgets a key whose request failed so it can be retried
+func release(queries *database.Queries, ctx context.Context, userID uuid.UUID, key string) {
+	if err := queries.ReleaseIdempotencyKey(ctx, database.ReleaseIdempotencyKeyParams{UserID: userID, Key: key}); err != nil {
+		log.Print(err)
+	}
+}
+
+// requestHash fingerprints the method, path and body so a key cannot be reused for a different request
+func requestHash(c *fiber.Ctx) string {
+	h := sha256.New()
+	h.Write([]byte(c.Method()))
+	h.Write([]byte{0})
+	h.Write([]byte(c.Path()))
+	h.Write([]byte{0})
+	h.Write(c.Body())
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// tokenUserID reads the user id from the token verified by Protected
+func tokenUserID(c *fiber.Ctx) (uuid.UUID, error) {
+	token, ok := c.Locals("user").(*jwt.Token)
+	if !ok {
+		return uuid.Nil, errors.New("missing token")
+	}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return uuid.Nil, errors.New("invalid token claims")
+	}
+	userID, _ := claims["userid"].(string)
+	return uuid.Parse(userID)
+}
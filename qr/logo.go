@@ -0,0 +1,34 @@
+package qr
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	_ "image/jpeg" // decodes JPEG logos, PNG is registered by the renderer
+	"os"
+)
+
+// Logo is an image drawn over the centre of QR codes, the original file is embedded in SVGs
+type Logo struct {
+	image       image.Image
+	data        []byte
+	contentType string
+}
+
+// LoadLogo reads a PNG or JPEG logo from path
+func LoadLogo(path string) (*Logo, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	img, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("decoding logo %v: %w", path, err)
+	}
+	if img.Bounds().Empty() {
+		return nil, fmt.Errorf("logo %v is empty", path)
+	}
+
+	return &Logo{image: img, data: data, contentType: "image/" + format}, nil
+}
@@ -0,0 +1,237 @@
+package qr
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"strconv"
+	"strings"
+
+	"github.com/skip2/go-qrcode"
+	xdraw "golang.org/x/image/draw"
+)
+
+// Formats a QR code can be rendered in
+const (
+	FormatPNG = "png"
+	FormatSVG = "svg"
+)
+
+// Limits of the rendering options, size is in pixels and margin in modules
+const (
+	DefaultSize   = 256
+	MinSize       = 64
+	MaxSize       = 2048
+	DefaultMargin = 4
+	MaxMargin     = 16
+	DefaultLevel  = "M"
+	// LogoLevel is always used with a logo, it recovers the modules hidden behind it
+	LogoLevel = "H"
+)
+
+// logoScale is the share of the symbol width covered by the logo, level H recovers up to 30% of the modules
+const logoScale = 0.2
+
+// ErrSizeTooSmall is returned when a PNG cannot give every module at least one pixel
+var ErrSizeTooSmall = errors.New("size is too small for this QR code, increase size or reduce margin")
+
+// levels maps the error correction levels of the QR code spec to the encoder levels
+var levels = map[string]qrcode.RecoveryLevel{
+	"L": qrcode.Low,
+	"M": qrcode.Medium,
+	"Q": qrcode.High,
+	"H": qrcode.Highest,
+}
+
+// Options controls how a QR code is rendered
+type Options struct {
+	Format     string
+	Size       int
+	Level      string
+	Foreground color.NRGBA
+	Background color.NRGBA
+	Margin     int
+	// Logo is drawn over the centre of the code when set
+	Logo *Logo
+}
+
+// DefaultOptions renders a black on white PNG
+func DefaultOptions() Options {
+	return Options{
+		Format:     FormatPNG,
+		Size:       DefaultSize,
+		Level:      DefaultLevel,
+		Foreground: color.NRGBA{A: 0xff},
+		Background: color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff},
+		Margin:     DefaultMargin,
+	}
+}
+
+// Validate reports the first option outside of its limits
+func (o Options) Validate() error {
+	if o.Format != FormatPNG && o.Format != FormatSVG {
+		return fmt.Errorf("format must be %v or %v", FormatPNG, FormatSVG)
+	}
+	if o.Size < MinSize || o.Size > MaxSize {
+		return fmt.Errorf("size must be between %v and %v", MinSize, MaxSize)
+	}
+	if _, ok := levels[o.Level]; !ok {
+		return errors.New("level must be one of L, M, Q or H")
+	}
+	if o.Margin < 0 || o.Margin > MaxMargin {
+		return fmt.Errorf("margin must be between 0 and %v", MaxMargin)
+	}
+	if o.Foreground == o.Background {
+		return errors.New("foreground and background colours must differ")
+	}
+	return nil
+}
+
+// ParseColor parses a hex colour as rrggbb or rrggbbaa, the leading # is optional
+func ParseColor(s string) (color.NRGBA, error) {
+	hex := strings.TrimPrefix(s, "#")
+	if len(hex) != 6 && len(hex) != 8 {
+		return color.NRGBA{}, fmt.Errorf("invalid colour %q, use rrggbb or rrggbbaa", s)
+	}
+	if len(hex) == 6 {
+		hex += "ff"
+	}
+
+	value, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return color.NRGBA{}, fmt.Errorf("invalid colour %q, use rrggbb or rrggbbaa", s)
+	}
+	return color.NRGBA{R: uint8(value >> 24), G: uint8(value >> 16), B: uint8(value >> 8), A: uint8(value)}, nil
+}
+
+// Render encodes content as a QR code and returns the image with its content type
+func Render(content string, opts Options) ([]byte, string, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, "", err
+	}
+	if opts.Logo != nil {
+		opts.Level = LogoLevel
+	}
+
+	code, err := qrcode.New(content, levels[opts.Level])
+	if err != nil {
+		return nil, "", err
+	}
+	code.DisableBorder = true
+	bitmap := code.Bitmap()
+
+	if opts.Format == FormatSVG {
+		return renderSVG(bitmap, opts), "image/svg+xml", nil
+	}
+
+	data, err := renderPNG(bitmap, opts)
+	if err != nil {
+		return nil, "", err
+	}
+	return data, "image/png", nil
+}
+
+// renderPNG scales every module to the same whole number of pixels, the leftover pixels widen the margin
+func renderPNG(bitmap [][]bool, opts Options) ([]byte, error) {
+	modules := len(bitmap) + 2*opts.Margin
+	scale := opts.Size / modules
+	if scale == 0 {
+		return nil, ErrSizeTooSmall
+	}
+	offset := (opts.Size-scale*modules)/2 + opts.Margin*scale
+
+	img := image.NewNRGBA(image.Rect(0, 0, opts.Size, opts.Size))
+	draw.Draw(img, img.Bounds(), image.NewUniform(opts.Background), image.Point{}, draw.Src)
+
+	foreground := image.NewUniform(opts.Foreground)
+	for y, row := range bitmap {
+		for x, dark := range row {
+			if dark {
+				module := image.Rect(offset+x*scale, offset+y*scale, offset+(x+1)*scale, offset+(y+1)*scale)
+				draw.Draw(img, module, foreground, image.Point{}, draw.Over)
+			}
+		}
+	}
+
+	if opts.Logo != nil {
+		symbol := len(bitmap) * scale
+		side := int(float64(symbol) * logoScale)
+		start := offset + (symbol-side)/2
+		area := image.Rect(start, start, start+side, start+side)
+
+		// one module of background keeps the logo apart from the modules around it
+		draw.Draw(img, area.Inset(-scale), image.NewUniform(opts.Background), image.Point{}, draw.Src)
+		xdraw.CatmullRom.Scale(img, fit(area, opts.Logo.image.Bounds()), opts.Logo.image, opts.Logo.image.Bounds(), xdraw.Over, nil)
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// renderSVG draws one path per row run of dark modules, the view box is measured in modules
+func renderSVG(bitmap [][]bool, opts Options) []byte {
+	modules := len(bitmap) + 2*opts.Margin
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" shape-rendering="crispEdges">`, opts.Size, opts.Size, modules, modules)
+	fmt.Fprintf(&buf, `<rect width="%d" height="%d"%s/>`, modules, modules, svgFill(opts.Background))
+
+	buf.WriteString(`<path d="`)
+	for y, row := range bitmap {
+		for x := 0; x < len(row); x++ {
+			if !row[x] {
+				continue
+			}
+			run := 1
+			for x+run < len(row) && row[x+run] {
+				run++
+			}
+			fmt.Fprintf(&buf, "M%d %dh%dv1h-%dz", x+opts.Margin, y+opts.Margin, run, run)
+			x += run - 1
+		}
+	}
+	fmt.Fprintf(&buf, `"%s/>`, svgFill(opts.Foreground))
+
+	if opts.Logo != nil {
+		symbol := float64(len(bitmap))
+		side := symbol * logoScale
+		start := float64(opts.Margin) + (symbol-side)/2
+
+		fmt.Fprintf(&buf, `<rect x="%g" y="%g" width="%g" height="%g"%s/>`, start-1, start-1, side+2, side+2, svgFill(opts.Background))
+		fmt.Fprintf(&buf, `<image x="%g" y="%g" width="%g" height="%g" preserveAspectRatio="xMidYMid meet" href="data:%s;base64,%s"/>`,
+			start, start, side, side, opts.Logo.contentType, base64.StdEncoding.EncodeToString(opts.Logo.data))
+	}
+
+	buf.WriteString(`</svg>`)
+	return buf.Bytes()
+}
+
+// svgFill returns the fill attributes of c, translucent colours also get a fill-opacity
+func svgFill(c color.NRGBA) string {
+	fill := fmt.Sprintf(` fill="#%02x%02x%02x"`, c.R, c.G, c.B)
+	if c.A != 0xff {
+		fill += fmt.Sprintf(` fill-opacity="%.3g"`, float64(c.A)/0xff)
+	}
+	return fill
+}
+
+// fit returns the largest rectangle centred in area with the aspect ratio of src
+func fit(area, src image.Rectangle) image.Rectangle {
+	width, height := area.Dx(), area.Dy()
+	if src.Dx() > src.Dy() {
+		height = width * src.Dy() / src.Dx()
+	} else {
+		width = height * src.Dx() / src.Dy()
+	}
+
+	min := area.Min.Add(image.Pt((area.Dx()-width)/2, (area.Dy()-height)/2))
+	return image.Rectangle{Min: min, Max: min.Add(image.Pt(width, height))}
+}
@@ -2,21 +2,47 @@ package router
 
 import (
 	"context"
-	"time"
+	"database/sql"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/limiter"
 	"github.com/redis/go-redis/v9"
 
+	"github.com/tin3ga/shortly/alias"
+	"github.com/tin3ga/shortly/config"
+	"github.com/tin3ga/shortly/geoip"
 	"github.com/tin3ga/shortly/handler"
 	"github.com/tin3ga/shortly/internal/database"
+	"github.com/tin3ga/shortly/metadata"
 	"github.com/tin3ga/shortly/middleware"
+	"github.com/tin3ga/shortly/qr"
 )
 
 // SetupRoutes setup router api
-func SetupRoutes(app *fiber.App, queries *database.Queries, ctx context.Context, rdb *redis.Client, ttl time.Duration, apiKey string, jwtsecret string) {
+func SetupRoutes(app *fiber.App, db *sql.DB, queries *database.Queries, ctx context.Context, rdb *redis.Client, geo *geoip.Reader, aliases *alias.Generators, policy *alias.Policy, logo *qr.Logo, cfg *config.ConfigParams) {
+	fetcher := metadata.NewHTTPFetcher(cfg.MetadataTimeout, cfg.MetadataMaxBytes)
+
 	app.Get("/", handler.Ping)
+	// "/{alias}+" previews a link, it must be registered before "/:link" which would match it too
+	app.Get("/:link\\+", func(c *fiber.Ctx) error {
+		return handler.PreviewLink(c, queries, ctx, rdb, cfg.CacheTTL, geo, policy)
+	})
 	app.Get("/:link", func(c *fiber.Ctx) error {
-		return handler.GetLink(c, queries, ctx, rdb, ttl)
+		return handler.GetLink(c, queries, ctx, rdb, cfg.CacheTTL, cfg.DefaultRedirectType, cfg.RedirectMaxAge, geo, cfg.NotLiveURL, cfg.DisabledLinkURL, policy)
+	})
+
+	// wrong passwords are rate limited per link, successful unlocks are not counted
+	unlockLimiter := limiter.New(limiter.Config{
+		Max:          cfg.UnlockMaxAttempts,
+		Expiration:   cfg.UnlockLockout,
+		KeyGenerator: handler.UnlockLimiterKey,
+		LimitReached: func(c *fiber.Ctx) error {
+			return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{"error": "Too many wrong passwords for this link! Try again later:)"})
+		},
+		SkipSuccessfulRequests: true,
+	})
+	app.Post("/:link", unlockLimiter, func(c *fiber.Ctx) error {
+		return handler.UnlockLink(c, queries, ctx, rdb, cfg.CacheTTL, geo, cfg.NotLiveURL, cfg.DisabledLinkURL, policy)
 	})
 
 	api := app.Group("api/v1")
@@ -34,7 +60,34 @@ func SetupRoutes(app *fiber.App, queries *database.Queries, ctx context.Context,
 	// auth
 	auth := api.Group("/auth")
 	auth.Post("/", func(c *fiber.Ctx) error {
-		return handler.Login(c, queries, ctx, jwtsecret)
+		return handler.Login(c, queries, ctx, cfg.JWTSecret)
+	})
+
+	// domains
+	domains := api.Group("/domains")
+	domains.Post("/", middleware.Protected(), func(c *fiber.Ctx) error {
+		return handler.CreateDomain(c, queries, ctx)
+	})
+	domains.Post("/:host/verify", middleware.Protected(), func(c *fiber.Ctx) error {
+		return handler.VerifyDomain(c, queries, ctx, rdb)
+	})
+	domains.Get("/", middleware.Protected(), func(c *fiber.Ctx) error {
+		return handler.GetDomains(c, queries, ctx)
+	})
+	domains.Delete("/:host", middleware.Protected(), func(c *fiber.Ctx) error {
+		return handler.DeleteDomain(c, db, queries, ctx, rdb)
+	})
+
+	// tags
+	tags := api.Group("/tags")
+	tags.Get("/", middleware.Protected(), func(c *fiber.Ctx) error {
+		return handler.GetTags(c, queries, ctx)
+	})
+	tags.Patch("/:name", middleware.Protected(), func(c *fiber.Ctx) error {
+		return handler.RenameTag(c, queries, ctx)
+	})
+	tags.Post("/:name/merge", middleware.Protected(), func(c *fiber.Ctx) error {
+		return handler.MergeTags(c, db, queries, ctx)
 	})
 
 	// shortly
@@ -46,11 +99,65 @@ func SetupRoutes(app *fiber.App, queries *database.Queries, ctx context.Context,
 	links.Get("/userlinks", middleware.Protected(), func(c *fiber.Ctx) error {
 		return handler.GetUserLinks(c, queries, ctx)
 	})
+	links.Get("/search", middleware.Protected(), func(c *fiber.Ctx) error {
+		return handler.SearchUserLinks(c, queries, ctx)
+	})
+	links.Get("/availability", middleware.Protected(), func(c *fiber.Ctx) error {
+		return handler.CheckAliasAvailability(c, queries, ctx, policy)
+	})
+	links.Get("/export", middleware.Protected(), func(c *fiber.Ctx) error {
+		return handler.ExportLinks(c, queries, ctx)
+	})
+	links.Post("/import", middleware.Protected(), func(c *fiber.Ctx) error {
+		return handler.ImportLinks(c, db, queries, ctx, cfg.APIKey, cfg.BulkWorkers, cfg.BulkMaxItems, aliases, policy)
+	})
 
-	links.Post("/shorten", middleware.Protected(), func(c *fiber.Ctx) error {
-		return handler.ShortenLink(c, queries, ctx, apiKey)
+	links.Post("/shorten", middleware.Protected(), middleware.Idempotency(queries, ctx), func(c *fiber.Ctx) error {
+		return handler.ShortenLink(c, db, queries, ctx, cfg.APIKey, fetcher, aliases, policy)
+	})
+	links.Post("/bulk", middleware.Protected(), middleware.Idempotency(queries, ctx), func(c *fiber.Ctx) error {
+		return handler.BulkShortenLinks(c, db, queries, ctx, cfg.APIKey, cfg.BulkWorkers, cfg.BulkMaxItems, aliases, policy)
 	})
 	links.Delete("/shorten", middleware.Protected(), func(c *fiber.Ctx) error {
-		return handler.DeleteLink(c, queries, ctx)
+		return handler.DeleteLink(c, queries, ctx, rdb)
+	})
+	links.Get("/trash", middleware.Protected(), func(c *fiber.Ctx) error {
+		return handler.GetTrash(c, queries, ctx)
+	})
+	links.Post("/trash/:alias/restore", middleware.Protected(), func(c *fiber.Ctx) error {
+		return handler.RestoreLink(c, queries, ctx, rdb, policy)
+	})
+	links.Delete("/trash/:alias", middleware.Protected(), func(c *fiber.Ctx) error {
+		return handler.DeleteTrashedLink(c, queries, ctx, policy)
+	})
+	links.Patch("/:alias", middleware.Protected(), func(c *fiber.Ctx) error {
+		return handler.EditLink(c, db, queries, ctx, rdb, cfg.APIKey, fetcher, policy)
+	})
+	links.Put("/:alias/password", middleware.Protected(), func(c *fiber.Ctx) error {
+		return handler.SetLinkPassword(c, queries, ctx, rdb)
+	})
+	links.Put("/:alias/targeting", middleware.Protected(), func(c *fiber.Ctx) error {
+		return handler.SetLinkTargeting(c, queries, ctx, rdb, cfg.APIKey)
+	})
+	links.Put("/:alias/geo", middleware.Protected(), func(c *fiber.Ctx) error {
+		return handler.SetLinkGeoTargeting(c, queries, ctx, rdb, cfg.APIKey)
+	})
+	links.Put("/:alias/schedule", middleware.Protected(), func(c *fiber.Ctx) error {
+		return handler.SetLinkSchedule(c, queries, ctx, rdb, cfg.APIKey)
+	})
+	links.Put("/:alias/variants", middleware.Protected(), func(c *fiber.Ctx) error {
+		return handler.SetLinkVariants(c, db, queries, ctx, rdb, cfg.APIKey)
+	})
+	links.Get("/:alias/variants", middleware.Protected(), func(c *fiber.Ctx) error {
+		return handler.GetLinkVariants(c, queries, ctx)
+	})
+	links.Get("/:alias/qr", middleware.Protected(), func(c *fiber.Ctx) error {
+		return handler.GetLinkQRCode(c, queries, ctx, cfg.URL, logo)
+	})
+	links.Get("/:alias/revisions", middleware.Protected(), func(c *fiber.Ctx) error {
+		return handler.GetLinkRevisions(c, queries, ctx)
+	})
+	links.Post("/:alias/revisions/:revision/rollback", middleware.Protected(), func(c *fiber.Ctx) error {
+		return handler.RollbackLink(c, db, queries, ctx, rdb, cfg.APIKey, fetcher, policy)
 	})
 }
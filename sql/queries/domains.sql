@@ -0,0 +1,30 @@
+-- name: CreateDomain :one
+INSERT INTO domains(id, user_id, host, verification_token)
+VALUES($1, $2, $3, $4)
+RETURNING *;
+
+-- name: GetVerifiedDomainByHost :one
+SELECT * FROM domains
+WHERE host = $1 AND verified_at IS NOT NULL;
+
+-- name: GetUserDomainByHost :one
+SELECT * FROM domains
+WHERE user_id = $1 AND host = $2;
+
+-- name: VerifyDomain :one
+UPDATE domains
+SET verified_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: GetDomain :one
+SELECT * FROM domains
+WHERE id = $1;
+
+-- name: GetUserDomains :many
+SELECT * FROM domains
+WHERE user_id = $1
+ORDER BY created_at DESC;
+
+-- name: DeleteDomain :exec
+DELETE FROM domains WHERE id = $1;
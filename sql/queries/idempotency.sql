@@ -0,0 +1,24 @@
+-- name: ClaimIdempotencyKey :one
+INSERT INTO idempotency_keys(user_id, key, request_hash)
+VALUES($1, $2, $3)
+ON CONFLICT (user_id, key) DO UPDATE
+SET request_hash = EXCLUDED.request_hash, status_code = NULL, response_body = NULL, created_at = NOW()
+WHERE idempotency_keys.created_at <= NOW() - sqlc.arg(ttl_seconds)::bigint * INTERVAL '1 second'
+RETURNING *;
+
+-- name: GetIdempotencyKey :one
+SELECT * FROM idempotency_keys
+WHERE user_id = $1 AND key = $2;
+
+-- name: SaveIdempotentResponse :exec
+UPDATE idempotency_keys
+SET status_code = $3, response_body = $4
+WHERE user_id = $1 AND key = $2;
+
+-- name: ReleaseIdempotencyKey :exec
+DELETE FROM idempotency_keys
+WHERE user_id = $1 AND key = $2 AND status_code IS NULL;
+
+-- name: PurgeIdempotencyKeys :execrows
+DELETE FROM idempotency_keys
+WHERE created_at <= NOW() - sqlc.arg(ttl_seconds)::bigint * INTERVAL '1 second';
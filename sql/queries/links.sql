@@ -1,28 +1,288 @@
 -- name: CreateShortLink :one
-INSERT INTO shortly(id, user_id, short_link, long_link)
-VALUES($1, $2, $3, $4)
+INSERT INTO shortly(
+    id, user_id, short_link, long_link, expires_at, max_clicks, password_hash, domain_id, folder, notes, scan_verdict, redirect_type,
+    forward_query, utm_source, utm_medium, utm_campaign, utm_term, utm_content, activate_at, normalized_link, alias_key,
+    alias_key_unique
+)
+VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22)
 RETURNING *;
 
 -- name: GetLongLink :one
 SELECT * FROM shortly
-WHERE short_link = $1
+WHERE short_link = $1 AND domain_id IS NULL AND deleted_at IS NULL
+LIMIT 1;
+
+-- name: GetDomainLink :one
+SELECT * FROM shortly
+WHERE short_link = $1 AND domain_id = $2 AND deleted_at IS NULL
+LIMIT 1;
+
+-- name: GetLinkByAliasKey :one
+SELECT * FROM shortly
+WHERE alias_key = $1 AND domain_id IS NOT DISTINCT FROM sqlc.narg(domain_id) AND deleted_at IS NULL
+ORDER BY created_at
 LIMIT 1;
 
 -- name: DeleteLink :exec
-DELETE FROM shortly WHERE short_link = $1;
+DELETE FROM shortly WHERE id = $1;
 
--- name: GetLinks :many
+-- name: TrashLink :one
+UPDATE shortly
+SET deleted_at = NOW(), updated_at = NOW()
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING *;
+
+-- name: RestoreLink :one
+UPDATE shortly
+SET deleted_at = NULL, updated_at = NOW()
+WHERE id = $1 AND deleted_at IS NOT NULL
+RETURNING *;
+
+-- name: GetTrashedLink :one
 SELECT * FROM shortly
-ORDER BY created_at DESC;
+WHERE short_link = $1 AND domain_id IS NOT DISTINCT FROM sqlc.narg(domain_id) AND deleted_at IS NOT NULL
+LIMIT 1;
+
+-- name: GetTrashedLinkByAliasKey :one
+SELECT * FROM shortly
+WHERE alias_key = $1 AND domain_id IS NOT DISTINCT FROM sqlc.narg(domain_id) AND deleted_at IS NOT NULL
+ORDER BY deleted_at DESC
+LIMIT 1;
+
+-- name: GetTrashedLinks :many
+SELECT * FROM shortly
+WHERE user_id = $1 AND deleted_at IS NOT NULL
+ORDER BY deleted_at DESC;
 
+-- name: PurgeTrashedLinks :execrows
+DELETE FROM shortly
+WHERE deleted_at IS NOT NULL AND deleted_at <= NOW() - sqlc.arg(retention_seconds)::bigint * INTERVAL '1 second';
 
--- name: IncrementClickCount :exec
+-- name: PurgeTrashedDomainLinks :execrows
+DELETE FROM shortly
+WHERE domain_id = $1 AND deleted_at IS NOT NULL;
+
+-- name: SetLinkEnabled :one
+UPDATE shortly
+SET enabled = $2, updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: ClaimClick :one
 UPDATE shortly
 SET click_count = click_count + 1, updated_at = NOW()
-WHERE short_link = $1;
+WHERE id = $1
+    AND (max_clicks IS NULL OR click_count < max_clicks)
+    AND (expires_at IS NULL OR expires_at > NOW())
+RETURNING click_count;
+
+-- name: CountQRScan :exec
+UPDATE shortly
+SET qr_scans = qr_scans + 1
+WHERE id = $1;
 
 
 -- name: GetUserLinks :many
 SELECT * FROM shortly
+WHERE user_id = $1 AND deleted_at IS NULL
+ORDER BY created_at DESC;
+
+-- name: ArchiveExpiredLinks :execrows
+WITH expired AS (
+    DELETE FROM shortly
+    WHERE expires_at IS NOT NULL AND expires_at <= NOW()
+    RETURNING id, user_id, short_link, long_link, click_count, created_at, expires_at, domain_id
+)
+INSERT INTO shortly_archive(id, user_id, short_link, long_link, click_count, created_at, expires_at, domain_id)
+SELECT id, user_id, short_link, long_link, click_count, created_at, expires_at, domain_id FROM expired;
+
+-- name: PurgeExpiredLinks :execrows
+DELETE FROM shortly
+WHERE expires_at IS NOT NULL AND expires_at <= NOW();
+
+-- name: GetArchivedLink :one
+SELECT * FROM shortly_archive
+WHERE short_link = $1 AND domain_id IS NOT DISTINCT FROM sqlc.narg(domain_id)
+ORDER BY archived_at DESC
+LIMIT 1;
+
+-- name: SetLinkPassword :exec
+UPDATE shortly
+SET password_hash = $2, updated_at = NOW()
+WHERE id = $1;
+
+-- name: GetLinkForUpdate :one
+SELECT * FROM shortly
+WHERE id = $1
+FOR UPDATE;
+
+-- name: UpdateLink :one
+UPDATE shortly
+SET short_link = $2, long_link = $3, redirect_type = $4, scan_verdict = $5, normalized_link = $6, alias_key = $7, alias_key_unique = $8,
+    updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: GetExistingAliases :many
+SELECT short_link FROM shortly
+WHERE short_link = ANY(sqlc.arg(aliases)::text[])
+    AND domain_id IS NOT DISTINCT FROM sqlc.narg(domain_id);
+
+-- name: GetNonASCIIAliases :many
+SELECT id, short_link, alias_key FROM shortly
+WHERE octet_length(short_link) <> char_length(short_link);
+
+-- name: SetAliasKey :exec
+UPDATE shortly
+SET alias_key = $2
+WHERE id = $1;
+
+-- name: GetExistingAliasKeys :many
+SELECT alias_key FROM shortly
+WHERE alias_key = ANY(sqlc.arg(alias_keys)::text[])
+    AND domain_id IS NOT DISTINCT FROM sqlc.narg(domain_id);
+
+-- name: SetLinkFolder :one
+UPDATE shortly
+SET folder = $2, updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: ListLinksByCreated :many
+SELECT * FROM shortly
+WHERE deleted_at IS NULL
+    AND (sqlc.narg(user_id)::uuid IS NULL OR user_id = sqlc.narg(user_id))
+    AND (sqlc.narg(folder)::text IS NULL OR folder = sqlc.narg(folder))
+    AND (sqlc.narg(tag)::text IS NULL OR EXISTS (
+        SELECT 1 FROM link_tags
+        JOIN tags ON tags.id = link_tags.tag_id
+        WHERE link_tags.link_id = shortly.id AND tags.name = sqlc.narg(tag)
+    ))
+    AND (sqlc.narg(created_from)::timestamp IS NULL OR created_at >= sqlc.narg(created_from))
+    AND (sqlc.narg(created_to)::timestamp IS NULL OR created_at < sqlc.narg(created_to))
+    AND (sqlc.narg(destination_host)::text IS NULL
+        OR lower(substring(long_link from '^[a-zA-Z][a-zA-Z0-9+.-]*://([^/?#:]+)')) = sqlc.narg(destination_host))
+    AND (sqlc.narg(cursor_created_at)::timestamp IS NULL
+        OR (created_at, id) < (sqlc.narg(cursor_created_at), sqlc.narg(cursor_id)::uuid))
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg(page_limit);
+
+-- name: ListLinksByClicks :many
+SELECT * FROM shortly
+WHERE deleted_at IS NULL
+    AND (sqlc.narg(user_id)::uuid IS NULL OR user_id = sqlc.narg(user_id))
+    AND (sqlc.narg(folder)::text IS NULL OR folder = sqlc.narg(folder))
+    AND (sqlc.narg(tag)::text IS NULL OR EXISTS (
+        SELECT 1 FROM link_tags
+        JOIN tags ON tags.id = link_tags.tag_id
+        WHERE link_tags.link_id = shortly.id AND tags.name = sqlc.narg(tag)
+    ))
+    AND (sqlc.narg(created_from)::timestamp IS NULL OR created_at >= sqlc.narg(created_from))
+    AND (sqlc.narg(created_to)::timestamp IS NULL OR created_at < sqlc.narg(created_to))
+    AND (sqlc.narg(destination_host)::text IS NULL
+        OR lower(substring(long_link from '^[a-zA-Z][a-zA-Z0-9+.-]*://([^/?#:]+)')) = sqlc.narg(destination_host))
+    AND (sqlc.narg(cursor_clicks)::int IS NULL
+        OR (click_count, id) < (sqlc.narg(cursor_clicks), sqlc.narg(cursor_id)::uuid))
+ORDER BY click_count DESC, id DESC
+LIMIT sqlc.arg(page_limit);
+
+-- name: ListLinksByAlias :many
+SELECT * FROM shortly
+WHERE deleted_at IS NULL
+    AND (sqlc.narg(user_id)::uuid IS NULL OR user_id = sqlc.narg(user_id))
+    AND (sqlc.narg(folder)::text IS NULL OR folder = sqlc.narg(folder))
+    AND (sqlc.narg(tag)::text IS NULL OR EXISTS (
+        SELECT 1 FROM link_tags
+        JOIN tags ON tags.id = link_tags.tag_id
+        WHERE link_tags.link_id = shortly.id AND tags.name = sqlc.narg(tag)
+    ))
+    AND (sqlc.narg(created_from)::timestamp IS NULL OR created_at >= sqlc.narg(created_from))
+    AND (sqlc.narg(created_to)::timestamp IS NULL OR created_at < sqlc.narg(created_to))
+    AND (sqlc.narg(destination_host)::text IS NULL
+        OR lower(substring(long_link from '^[a-zA-Z][a-zA-Z0-9+.-]*://([^/?#:]+)')) = sqlc.narg(destination_host))
+    AND (sqlc.narg(cursor_alias)::text IS NULL
+        OR (short_link, id) > (sqlc.narg(cursor_alias), sqlc.narg(cursor_id)::uuid))
+ORDER BY short_link ASC, id ASC
+LIMIT sqlc.arg(page_limit);
+
+-- name: SetLinkNotes :one
+UPDATE shortly
+SET notes = $2, updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: SetLinkMetadata :exec
+UPDATE shortly
+SET title = $2,
+    description = $3,
+    favicon_url = $4,
+    og_title = $5,
+    og_description = $6,
+    og_image = $7,
+    metadata_fetched_at = NOW()
+WHERE id = $1;
+
+-- name: SetLinkQueryOptions :one
+UPDATE shortly
+SET forward_query = $2,
+    utm_source = $3,
+    utm_medium = $4,
+    utm_campaign = $5,
+    utm_term = $6,
+    utm_content = $7,
+    updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: SetLinkTargetingRules :one
+UPDATE shortly
+SET targeting_rules = $2,
+    updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: SetLinkGeoRules :one
+UPDATE shortly
+SET geo_rules = $2,
+    blocked_countries = $3,
+    updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: SetLinkActivation :one
+UPDATE shortly
+SET activate_at = $2,
+    updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: SetLinkSchedule :one
+UPDATE shortly
+SET schedule = $2,
+    schedule_timezone = $3,
+    updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: FindReusableLink :one
+SELECT * FROM shortly
 WHERE user_id = $1
-ORDER BY created_at DESC;
\ No newline at end of file
+    AND normalized_link = $2
+    AND domain_id IS NOT DISTINCT FROM sqlc.narg(domain_id)
+    AND deleted_at IS NULL
+    AND enabled
+    AND password_hash IS NULL
+    AND (expires_at IS NULL OR expires_at > NOW())
+    AND (max_clicks IS NULL OR click_count < max_clicks)
+    AND (activate_at IS NULL OR activate_at <= NOW())
+    AND redirect_type IS NULL
+    AND NOT forward_query
+    AND utm_source IS NULL AND utm_medium IS NULL AND utm_campaign IS NULL AND utm_term IS NULL AND utm_content IS NULL
+    AND folder IS NULL
+    AND NOT EXISTS (SELECT 1 FROM link_tags WHERE link_tags.link_id = shortly.id)
+    AND targeting_rules = '[]' AND geo_rules = '[]' AND blocked_countries = '{}' AND variants = '[]' AND schedule = '[]'
+ORDER BY created_at DESC
+LIMIT 1;
+
+-- name: NextAliasSequence :one
+SELECT nextval('alias_sequence')::bigint AS next;
@@ -0,0 +1,17 @@
+-- name: CreateLinkRevision :one
+INSERT INTO link_revisions(id, link_id, revision, short_link, long_link, redirect_type)
+VALUES($1, $2, (SELECT COALESCE(MAX(revision), 0) + 1 FROM link_revisions WHERE link_id = $2), $3, $4, $5)
+RETURNING *;
+
+-- name: CountLinkRevisions :one
+SELECT COUNT(*) FROM link_revisions
+WHERE link_id = $1;
+
+-- name: GetLinkRevisions :many
+SELECT * FROM link_revisions
+WHERE link_id = $1
+ORDER BY revision DESC;
+
+-- name: GetLinkRevision :one
+SELECT * FROM link_revisions
+WHERE link_id = $1 AND revision = $2;
@@ -0,0 +1,22 @@
+-- name: SearchUserLinks :many
+WITH matches AS (
+    SELECT shortly.*, ts_rank(
+        shortly_search_vector(short_link, long_link, notes, title, description),
+        websearch_to_tsquery('simple', sqlc.arg(query))
+    ) AS rank
+    FROM shortly
+    WHERE user_id = sqlc.arg(user_id) AND deleted_at IS NULL
+        AND shortly_search_vector(short_link, long_link, notes, title, description) @@ websearch_to_tsquery('simple', sqlc.arg(query))
+)
+SELECT id, user_id, short_link, long_link, click_count, created_at, updated_at, expires_at, max_clicks, redirect_type, domain_id, folder, notes,
+    title, description, favicon_url, og_title, og_description, og_image, metadata_fetched_at, scan_verdict,
+    forward_query, utm_source, utm_medium, utm_campaign, utm_term, utm_content, targeting_rules,
+    geo_rules, blocked_countries, variants, sticky_variants, activate_at, schedule, schedule_timezone,
+    enabled, deleted_at, normalized_link, alias_key, qr_scans, alias_key_unique, rank,
+    ts_headline('simple', concat_ws(' ', short_link, title, long_link, notes, description), websearch_to_tsquery('simple', sqlc.arg(query)),
+        'StartSel=[[[, StopSel=]]], MaxFragments=2, MinWords=3, MaxWords=12')::text AS headline
+FROM matches
+WHERE sqlc.narg(cursor_rank)::real IS NULL
+    OR (rank, id) < (sqlc.narg(cursor_rank), sqlc.narg(cursor_id)::uuid)
+ORDER BY rank DESC, id DESC
+LIMIT sqlc.arg(page_limit);
@@ -0,0 +1,47 @@
+-- name: UpsertTag :one
+INSERT INTO tags(id, user_id, name)
+VALUES($1, $2, $3)
+ON CONFLICT (user_id, name) DO UPDATE SET name = EXCLUDED.name
+RETURNING *;
+
+-- name: GetTagByName :one
+SELECT * FROM tags
+WHERE user_id = $1 AND name = $2;
+
+-- name: GetUserTags :many
+SELECT tags.id, tags.name, tags.created_at, COUNT(link_tags.link_id) AS link_count
+FROM tags
+LEFT JOIN link_tags ON link_tags.tag_id = tags.id
+WHERE tags.user_id = $1
+GROUP BY tags.id
+ORDER BY tags.name;
+
+-- name: RenameTag :one
+UPDATE tags
+SET name = $2
+WHERE id = $1
+RETURNING *;
+
+-- name: DeleteTag :exec
+DELETE FROM tags WHERE id = $1;
+
+-- name: AddLinkTag :exec
+INSERT INTO link_tags(link_id, tag_id)
+VALUES($1, $2)
+ON CONFLICT DO NOTHING;
+
+-- name: ClearLinkTags :exec
+DELETE FROM link_tags WHERE link_id = $1;
+
+-- name: MergeTagLinks :exec
+INSERT INTO link_tags(link_id, tag_id)
+SELECT link_id, sqlc.arg(target_id)::uuid FROM link_tags
+WHERE tag_id = sqlc.arg(source_id)::uuid
+ON CONFLICT DO NOTHING;
+
+-- name: GetTagsForLinks :many
+SELECT link_tags.link_id, tags.name
+FROM link_tags
+JOIN tags ON tags.id = link_tags.tag_id
+WHERE link_tags.link_id = ANY(sqlc.arg(link_ids)::uuid[])
+ORDER BY tags.name;
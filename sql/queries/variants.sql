@@ -0,0 +1,20 @@
+-- name: SetLinkVariants :one
+UPDATE shortly
+SET variants = $2,
+    sticky_variants = $3,
+    updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: RecordVariantClick :exec
+INSERT INTO variant_clicks(link_id, variant, clicks)
+VALUES($1, $2, 1)
+ON CONFLICT (link_id, variant) DO UPDATE SET clicks = variant_clicks.clicks + 1;
+
+-- name: GetVariantClicks :many
+SELECT * FROM variant_clicks
+WHERE link_id = $1;
+
+-- name: DeleteStaleVariantClicks :exec
+DELETE FROM variant_clicks
+WHERE link_id = sqlc.arg(link_id) AND NOT (variant = ANY(sqlc.arg(variants)::text[]));
@@ -0,0 +1,31 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE shortly
+ADD COLUMN expires_at TIMESTAMP;
+
+CREATE INDEX idx_shortly_expires_at ON shortly(expires_at)
+WHERE expires_at IS NOT NULL;
+
+CREATE TABLE shortly_archive(
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    short_link TEXT NOT NULL,
+    long_link TEXT NOT NULL,
+    click_count INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    archived_at TIMESTAMP  DEFAULT NOW() NOT NULL
+);
+
+CREATE INDEX idx_shortly_archive_short_link ON shortly_archive(short_link);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE shortly_archive;
+
+DROP INDEX idx_shortly_expires_at;
+
+ALTER TABLE shortly
+DROP COLUMN expires_at;
+-- +goose StatementEnd
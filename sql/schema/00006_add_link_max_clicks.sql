@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE shortly
+ADD COLUMN max_clicks INT,
+ADD CONSTRAINT positive_max_clicks CHECK (max_clicks IS NULL OR max_clicks > 0);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE shortly
+DROP CONSTRAINT positive_max_clicks,
+DROP COLUMN max_clicks;
+-- +goose StatementEnd
@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE shortly
+ADD COLUMN redirect_type INT,
+ADD CONSTRAINT valid_redirect_type CHECK (redirect_type IN (301, 302, 307, 308));
+
+CREATE TABLE link_revisions(
+    id UUID PRIMARY KEY,
+    link_id UUID NOT NULL REFERENCES shortly(id) ON DELETE CASCADE,
+    revision INT NOT NULL,
+    short_link TEXT NOT NULL,
+    long_link TEXT NOT NULL,
+    redirect_type INT,
+    created_at TIMESTAMP  DEFAULT NOW() NOT NULL,
+    CONSTRAINT unique_link_revision UNIQUE (link_id, revision)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE link_revisions;
+
+ALTER TABLE shortly
+DROP CONSTRAINT valid_redirect_type,
+DROP COLUMN redirect_type;
+-- +goose StatementEnd
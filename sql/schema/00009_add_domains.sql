@@ -0,0 +1,45 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE domains(
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    host TEXT UNIQUE NOT NULL,
+    created_at TIMESTAMP  DEFAULT NOW() NOT NULL
+);
+
+ALTER TABLE shortly
+ADD COLUMN domain_id UUID REFERENCES domains(id);
+
+ALTER TABLE shortly_archive
+ADD COLUMN domain_id UUID;
+
+-- aliases are unique per domain, links without a domain share the default domain
+ALTER TABLE shortly
+DROP CONSTRAINT unique_short_link;
+
+CREATE UNIQUE INDEX unique_short_link ON shortly(short_link)
+WHERE domain_id IS NULL;
+
+CREATE UNIQUE INDEX unique_domain_short_link ON shortly(domain_id, short_link)
+WHERE domain_id IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX unique_domain_short_link;
+
+DROP INDEX unique_short_link;
+
+DELETE FROM shortly WHERE domain_id IS NOT NULL;
+
+ALTER TABLE shortly
+ADD CONSTRAINT unique_short_link UNIQUE (short_link);
+
+ALTER TABLE shortly_archive
+DROP COLUMN domain_id;
+
+ALTER TABLE shortly
+DROP COLUMN domain_id;
+
+DROP TABLE domains;
+-- +goose StatementEnd
@@ -0,0 +1,35 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE shortly
+ADD COLUMN folder TEXT;
+
+CREATE INDEX idx_shortly_user_folder ON shortly(user_id, folder);
+
+CREATE TABLE tags(
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    created_at TIMESTAMP  DEFAULT NOW() NOT NULL,
+    CONSTRAINT unique_user_tag UNIQUE (user_id, name)
+);
+
+CREATE TABLE link_tags(
+    link_id UUID NOT NULL REFERENCES shortly(id) ON DELETE CASCADE,
+    tag_id UUID NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+    PRIMARY KEY (link_id, tag_id)
+);
+
+CREATE INDEX idx_link_tags_tag ON link_tags(tag_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE link_tags;
+
+DROP TABLE tags;
+
+DROP INDEX idx_shortly_user_folder;
+
+ALTER TABLE shortly
+DROP COLUMN folder;
+-- +goose StatementEnd
@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+-- keyset pagination indexes, one per sort order of the link listings
+CREATE INDEX idx_shortly_created ON shortly(created_at DESC, id DESC);
+
+CREATE INDEX idx_shortly_user_created ON shortly(user_id, created_at DESC, id DESC);
+
+CREATE INDEX idx_shortly_user_clicks ON shortly(user_id, click_count DESC, id DESC);
+
+CREATE INDEX idx_shortly_user_alias ON shortly(user_id, short_link, id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX idx_shortly_user_alias;
+
+DROP INDEX idx_shortly_user_clicks;
+
+DROP INDEX idx_shortly_user_created;
+
+DROP INDEX idx_shortly_created;
+-- +goose StatementEnd
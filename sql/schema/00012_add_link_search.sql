@@ -0,0 +1,32 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE shortly
+ADD COLUMN notes TEXT;
+
+-- words of the alias, the destination host and path, and the notes; the 'simple' configuration
+-- keeps aliases and host names intact instead of stemming them as English words
+CREATE FUNCTION shortly_search_vector(short_link TEXT, long_link TEXT, notes TEXT)
+RETURNS tsvector
+LANGUAGE SQL
+IMMUTABLE
+AS $$
+    SELECT setweight(to_tsvector('simple', short_link), 'A')
+        || setweight(to_tsvector('simple', regexp_replace(
+            coalesce(substring(long_link from '^[a-zA-Z][a-zA-Z0-9+.-]*://([^?#]*)'), ''),
+            '[^[:alnum:]]+', ' ', 'g')), 'B')
+        || setweight(to_tsvector('simple', coalesce(notes, '')), 'C')
+$$;
+
+CREATE INDEX idx_shortly_search ON shortly
+USING GIN (shortly_search_vector(short_link, long_link, notes));
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX idx_shortly_search;
+
+DROP FUNCTION shortly_search_vector(TEXT, TEXT, TEXT);
+
+ALTER TABLE shortly
+DROP COLUMN notes;
+-- +goose StatementEnd
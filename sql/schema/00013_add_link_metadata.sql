@@ -0,0 +1,64 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE shortly
+ADD COLUMN title TEXT,
+ADD COLUMN description TEXT,
+ADD COLUMN favicon_url TEXT,
+ADD COLUMN og_title TEXT,
+ADD COLUMN og_description TEXT,
+ADD COLUMN og_image TEXT,
+ADD COLUMN metadata_fetched_at TIMESTAMP;
+
+-- page titles and descriptions become searchable
+DROP INDEX idx_shortly_search;
+
+DROP FUNCTION shortly_search_vector(TEXT, TEXT, TEXT);
+
+CREATE FUNCTION shortly_search_vector(short_link TEXT, long_link TEXT, notes TEXT, title TEXT, description TEXT)
+RETURNS tsvector
+LANGUAGE SQL
+IMMUTABLE
+AS $$
+    SELECT setweight(to_tsvector('simple', short_link), 'A')
+        || setweight(to_tsvector('simple', coalesce(title, '')), 'A')
+        || setweight(to_tsvector('simple', regexp_replace(
+            coalesce(substring(long_link from '^[a-zA-Z][a-zA-Z0-9+.-]*://([^?#]*)'), ''),
+            '[^[:alnum:]]+', ' ', 'g')), 'B')
+        || setweight(to_tsvector('simple', coalesce(notes, '')), 'C')
+        || setweight(to_tsvector('simple', coalesce(description, '')), 'D')
+$$;
+
+CREATE INDEX idx_shortly_search ON shortly
+USING GIN (shortly_search_vector(short_link, long_link, notes, title, description));
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX idx_shortly_search;
+
+DROP FUNCTION shortly_search_vector(TEXT, TEXT, TEXT, TEXT, TEXT);
+
+CREATE FUNCTION shortly_search_vector(short_link TEXT, long_link TEXT, notes TEXT)
+RETURNS tsvector
+LANGUAGE SQL
+IMMUTABLE
+AS $$
+    SELECT setweight(to_tsvector('simple', short_link), 'A')
+        || setweight(to_tsvector('simple', regexp_replace(
+            coalesce(substring(long_link from '^[a-zA-Z][a-zA-Z0-9+.-]*://([^?#]*)'), ''),
+            '[^[:alnum:]]+', ' ', 'g')), 'B')
+        || setweight(to_tsvector('simple', coalesce(notes, '')), 'C')
+$$;
+
+CREATE INDEX idx_shortly_search ON shortly
+USING GIN (shortly_search_vector(short_link, long_link, notes));
+
+ALTER TABLE shortly
+DROP COLUMN metadata_fetched_at,
+DROP COLUMN og_image,
+DROP COLUMN og_description,
+DROP COLUMN og_title,
+DROP COLUMN favicon_url,
+DROP COLUMN description,
+DROP COLUMN title;
+-- +goose StatementEnd
@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+-- result of the urlscan check when the destination was last set, NULL when unknown
+ALTER TABLE shortly
+ADD COLUMN scan_verdict TEXT;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE shortly
+DROP COLUMN scan_verdict;
+-- +goose StatementEnd
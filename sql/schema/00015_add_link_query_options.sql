@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE shortly
+ADD COLUMN forward_query BOOLEAN NOT NULL DEFAULT FALSE,
+ADD COLUMN utm_source TEXT,
+ADD COLUMN utm_medium TEXT,
+ADD COLUMN utm_campaign TEXT,
+ADD COLUMN utm_term TEXT,
+ADD COLUMN utm_content TEXT;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE shortly
+DROP COLUMN utm_content,
+DROP COLUMN utm_term,
+DROP COLUMN utm_campaign,
+DROP COLUMN utm_medium,
+DROP COLUMN utm_source,
+DROP COLUMN forward_query;
+-- +goose StatementEnd
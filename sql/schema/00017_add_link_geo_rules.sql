@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE shortly
+ADD COLUMN geo_rules JSONB NOT NULL DEFAULT '[]',
+ADD COLUMN blocked_countries TEXT[] NOT NULL DEFAULT '{}';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE shortly
+DROP COLUMN blocked_countries,
+DROP COLUMN geo_rules;
+-- +goose StatementEnd
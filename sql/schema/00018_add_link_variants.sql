@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE shortly
+ADD COLUMN variants JSONB NOT NULL DEFAULT '[]',
+ADD COLUMN sticky_variants BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TABLE variant_clicks(
+    link_id UUID NOT NULL REFERENCES shortly(id) ON DELETE CASCADE,
+    variant TEXT NOT NULL,
+    clicks BIGINT NOT NULL DEFAULT 0,
+    PRIMARY KEY (link_id, variant)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE variant_clicks;
+
+ALTER TABLE shortly
+DROP COLUMN sticky_variants,
+DROP COLUMN variants;
+-- +goose StatementEnd
@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE shortly
+ADD COLUMN activate_at TIMESTAMP,
+ADD COLUMN schedule JSONB NOT NULL DEFAULT '[]',
+ADD COLUMN schedule_timezone TEXT NOT NULL DEFAULT 'UTC';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE shortly
+DROP COLUMN schedule_timezone,
+DROP COLUMN schedule,
+DROP COLUMN activate_at;
+-- +goose StatementEnd
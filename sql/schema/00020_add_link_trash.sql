@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE shortly
+ADD COLUMN enabled BOOLEAN NOT NULL DEFAULT TRUE,
+ADD COLUMN deleted_at TIMESTAMP;
+
+CREATE INDEX idx_shortly_deleted_at ON shortly(deleted_at) WHERE deleted_at IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX idx_shortly_deleted_at;
+
+ALTER TABLE shortly
+DROP COLUMN deleted_at,
+DROP COLUMN enabled;
+-- +goose StatementEnd
@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE shortly
+ADD COLUMN normalized_link TEXT;
+
+CREATE INDEX idx_shortly_user_normalized_link ON shortly(user_id, normalized_link) WHERE deleted_at IS NULL;
+
+CREATE TABLE idempotency_keys(
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    key TEXT NOT NULL,
+    request_hash TEXT NOT NULL,
+    status_code INT,
+    response_body BYTEA,
+    created_at TIMESTAMP  DEFAULT NOW() NOT NULL,
+    PRIMARY KEY (user_id, key)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE idempotency_keys;
+
+DROP INDEX idx_shortly_user_normalized_link;
+
+ALTER TABLE shortly
+DROP COLUMN normalized_link;
+-- +goose StatementEnd
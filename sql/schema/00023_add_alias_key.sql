@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+-- alias_key is the alias with case folded and look-alike letters replaced, existing aliases are lowercased
+-- which matches alias.Key for ASCII aliases, worker.BackfillAliasKeys fixes the others on startup
+ALTER TABLE shortly
+ADD COLUMN alias_key TEXT;
+
+UPDATE shortly SET alias_key = lower(short_link);
+
+ALTER TABLE shortly
+ALTER COLUMN alias_key SET NOT NULL;
+
+CREATE INDEX idx_shortly_alias_key ON shortly(alias_key, domain_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX idx_shortly_alias_key;
+
+ALTER TABLE shortly
+DROP COLUMN alias_key;
+-- +goose StatementEnd
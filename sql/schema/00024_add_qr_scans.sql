@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+-- visits through a QR code carry ?src=qr, they are counted apart from the other clicks
+ALTER TABLE shortly
+ADD COLUMN qr_scans INT NOT NULL DEFAULT 0;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE shortly
+DROP COLUMN qr_scans;
+-- +goose StatementEnd
@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+-- alias_key_unique marks links saved while aliases ignore case, their alias keys are unique per domain so two
+-- concurrent requests cannot create Foo and foo. Links saved while aliases match case may share a key.
+-- Trashed links keep their alias like unique_short_link does.
+ALTER TABLE shortly
+ADD COLUMN alias_key_unique BOOLEAN NOT NULL DEFAULT false;
+
+CREATE UNIQUE INDEX unique_alias_key ON shortly(COALESCE(domain_id, '00000000-0000-0000-0000-000000000000'::uuid), alias_key)
+WHERE alias_key_unique;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX unique_alias_key;
+
+ALTER TABLE shortly
+DROP COLUMN alias_key_unique;
+-- +goose StatementEnd
@@ -0,0 +1,42 @@
+-- +goose Up
+-- +goose StatementBegin
+-- a domain resolves links only once its owner has published verification_token in a DNS TXT record.
+-- Hosts stay unique among verified domains, unverified claims cannot lock the real owner out of a host.
+-- Domains registered before verification existed have to be verified again.
+ALTER TABLE domains
+ADD COLUMN verification_token TEXT NOT NULL DEFAULT md5(random()::text),
+ADD COLUMN verified_at TIMESTAMP;
+
+ALTER TABLE domains
+ALTER COLUMN verification_token DROP DEFAULT;
+
+ALTER TABLE domains
+DROP CONSTRAINT domains_host_key;
+
+CREATE UNIQUE INDEX unique_verified_host ON domains(host)
+WHERE verified_at IS NOT NULL;
+
+CREATE UNIQUE INDEX unique_user_host ON domains(user_id, host);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX unique_user_host;
+
+DROP INDEX unique_verified_host;
+
+-- keep the verified or else the oldest claim of every host
+DELETE FROM domains d
+USING domains o
+WHERE d.host = o.host
+AND d.id <> o.id
+AND (d.verified_at IS NULL AND o.verified_at IS NOT NULL
+    OR (d.verified_at IS NULL) = (o.verified_at IS NULL) AND (d.created_at, d.id) > (o.created_at, o.id));
+
+ALTER TABLE domains
+ADD CONSTRAINT domains_host_key UNIQUE (host);
+
+ALTER TABLE domains
+DROP COLUMN verified_at,
+DROP COLUMN verification_token;
+-- +goose StatementEnd
@@ -0,0 +1,80 @@
+package targeting
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// MaxCountries is the number of countries a link can block
+const MaxCountries = 250
+
+// GeoRule sends visitors from one of Countries to Url
+type GeoRule struct {
+	// ISO 3166-1 alpha-2 country codes such as DE or US
+	Countries []string `json:"countries"`
+	Url       string   `json:"url"`
+}
+
+// MatchCountry returns the destination of the first rule listing country, false when no rule does
+func MatchCountry(rules []GeoRule, country string) (string, bool) {
+	if country == "" {
+		return "", false
+	}
+	for _, rule := range rules {
+		if slices.Contains(rule.Countries, country) {
+			return rule.Url, true
+		}
+	}
+	return "", false
+}
+
+// NormalizeCountries uppercases country codes, drops duplicates and checks that each is two letters
+func NormalizeCountries(countries []string) ([]string, error) {
+	if len(countries) > MaxCountries {
+		return nil, fmt.Errorf("at most %d countries are allowed", MaxCountries)
+	}
+
+	normalized := make([]string, 0, len(countries))
+	for _, country := range countries {
+		country = strings.ToUpper(strings.TrimSpace(country))
+		if !isCountryCode(country) {
+			return nil, fmt.Errorf("%q is not a two letter country code", country)
+		}
+		if !slices.Contains(normalized, country) {
+			normalized = append(normalized, country)
+		}
+	}
+	return normalized, nil
+}
+
+// NormalizeGeo checks geo rules, every rule needs at least one country and a url
+func NormalizeGeo(rules []GeoRule) ([]GeoRule, error) {
+	if len(rules) > MaxRules {
+		return nil, fmt.Errorf("a link can have at most %d geo rules", MaxRules)
+	}
+
+	normalized := make([]GeoRule, 0, len(rules))
+	for i, rule := range rules {
+		countries, err := NormalizeCountries(rule.Countries)
+		if err != nil {
+			return nil, fmt.Errorf("rule %d: %w", i+1, err)
+		}
+		if len(countries) == 0 {
+			return nil, fmt.Errorf("rule %d needs at least one country", i+1)
+		}
+		rule.Countries = countries
+		rule.Url = strings.TrimSpace(rule.Url)
+		if rule.Url == "" {
+			return nil, fmt.Errorf("rule %d needs a url", i+1)
+		}
+		normalized = append(normalized, rule)
+	}
+	return normalized, nil
+}
+
+func isCountryCode(country string) bool {
+	return len(country) == 2 &&
+		country[0] >= 'A' && country[0] <= 'Z' &&
+		country[1] >= 'A' && country[1] <= 'Z'
+}
@@ -0,0 +1,126 @@
+package targeting
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// clockLayout is the format of window start and end times
+const clockLayout = "15:04"
+
+var weekdays = []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+
+// Window sends visitors to Url on the given days between Start and End in the schedule's timezone.
+// A window that ends before it starts runs past midnight into the next day.
+type Window struct {
+	// Days of the week: mon, tue, wed, thu, fri, sat or sun, empty means every day
+	Days []string `json:"days,omitempty"`
+	// 24 hour clock time such as 09:00
+	Start string `json:"start"`
+	// 24 hour clock time such as 17:30, the window ends just before it
+	End string `json:"end"`
+	Url string `json:"url"`
+}
+
+// MatchSchedule returns the destination of the first window open at now in loc, false when none is
+func MatchSchedule(windows []Window, loc *time.Location, now time.Time) (string, bool) {
+	now = now.In(loc)
+	minute := now.Hour()*60 + now.Minute()
+	today := weekdays[now.Weekday()]
+	yesterday := weekdays[(now.Weekday()+6)%7]
+
+	for _, window := range windows {
+		start, errStart := minuteOfDay(window.Start)
+		end, errEnd := minuteOfDay(window.End)
+		if errStart != nil || errEnd != nil {
+			continue
+		}
+
+		if start < end {
+			if window.onDay(today) && minute >= start && minute < end {
+				return window.Url, true
+			}
+			continue
+		}
+
+		// overnight windows belong to the day they start on
+		if (window.onDay(today) && minute >= start) || (window.onDay(yesterday) && minute < end) {
+			return window.Url, true
+		}
+	}
+	return "", false
+}
+
+func (w Window) onDay(day string) bool {
+	return len(w.Days) == 0 || slices.Contains(w.Days, day)
+}
+
+// parseDay accepts short and full day names in any case, monday is returned as mon
+func parseDay(day string) (string, bool) {
+	day = strings.ToLower(strings.TrimSpace(day))
+	for i, short := range weekdays {
+		if day == short || day == strings.ToLower(time.Weekday(i).String()) {
+			return short, true
+		}
+	}
+	return "", false
+}
+
+func minuteOfDay(clock string) (int, error) {
+	t, err := time.Parse(clockLayout, clock)
+	if err != nil {
+		return 0, err
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// NormalizeSchedule checks the windows of a schedule and its IANA timezone, an empty timezone is UTC
+func NormalizeSchedule(windows []Window, timezone string) ([]Window, string, error) {
+	timezone = strings.TrimSpace(timezone)
+	if timezone == "" {
+		timezone = "UTC"
+	}
+	if _, err := time.LoadLocation(timezone); err != nil {
+		return nil, "", fmt.Errorf("unknown timezone %q", timezone)
+	}
+
+	if len(windows) > MaxRules {
+		return nil, "", fmt.Errorf("a schedule can have at most %d windows", MaxRules)
+	}
+
+	normalized := make([]Window, 0, len(windows))
+	for i, window := range windows {
+		days := make([]string, 0, len(window.Days))
+		for _, day := range window.Days {
+			day, ok := parseDay(day)
+			if !ok {
+				return nil, "", fmt.Errorf("window %d: days must be mon, tue, wed, thu, fri, sat or sun", i+1)
+			}
+			if !slices.Contains(days, day) {
+				days = append(days, day)
+			}
+		}
+		window.Days = days
+
+		start, err := minuteOfDay(window.Start)
+		if err != nil {
+			return nil, "", fmt.Errorf("window %d: start must be a time such as 09:00", i+1)
+		}
+		end, err := minuteOfDay(window.End)
+		if err != nil {
+			return nil, "", fmt.Errorf("window %d: end must be a time such as 17:30", i+1)
+		}
+		if start == end {
+			return nil, "", fmt.Errorf("window %d: start and end must differ", i+1)
+		}
+
+		window.Url = strings.TrimSpace(window.Url)
+		if window.Url == "" {
+			return nil, "", fmt.Errorf("window %d needs a url", i+1)
+		}
+		normalized = append(normalized, window)
+	}
+	return normalized, timezone, nil
+}
@@ -0,0 +1,148 @@
+package targeting
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/mssola/useragent"
+)
+
+// Operating systems a rule can match on
+const (
+	OSIOS      = "ios"
+	OSAndroid  = "android"
+	OSWindows  = "windows"
+	OSMacOS    = "macos"
+	OSLinux    = "linux"
+	OSChromeOS = "chromeos"
+	OSOther    = "other"
+)
+
+// Device types a rule can match on
+const (
+	DeviceMobile  = "mobile"
+	DeviceTablet  = "tablet"
+	DeviceDesktop = "desktop"
+)
+
+// MaxRules is the number of rules a link can have
+const MaxRules = 10
+
+var (
+	validOS      = []string{OSIOS, OSAndroid, OSWindows, OSMacOS, OSLinux, OSChromeOS, OSOther}
+	validDevices = []string{DeviceMobile, DeviceTablet, DeviceDesktop}
+)
+
+// Client is what the rules know about a visitor
+type Client struct {
+	OS      string
+	Device  string
+	Browser string
+}
+
+// Rule sends matching visitors to Url, empty conditions match every visitor
+type Rule struct {
+	// One of ios, android, windows, macos, linux, chromeos or other
+	OS string `json:"os,omitempty"`
+	// One of mobile, tablet or desktop
+	Device string `json:"device,omitempty"`
+	// Browser name such as chrome, safari, firefox, edge or opera
+	Browser string `json:"browser,omitempty"`
+	Url     string `json:"url"`
+}
+
+// Classify reads the operating system, device type and browser from a User-Agent header
+func Classify(userAgent string) Client {
+	ua := useragent.New(userAgent)
+	name, _ := ua.Browser()
+
+	client := Client{
+		OS:      classifyOS(ua),
+		Device:  DeviceDesktop,
+		Browser: strings.ToLower(name),
+	}
+
+	switch {
+	// Android tablets leave out the Mobile token, iPads report their own platform
+	case ua.Platform() == "iPad" || (client.OS == OSAndroid && !strings.Contains(userAgent, "Mobile")):
+		client.Device = DeviceTablet
+	case ua.Mobile():
+		client.Device = DeviceMobile
+	}
+
+	return client
+}
+
+func classifyOS(ua *useragent.UserAgent) string {
+	switch ua.Platform() {
+	case "iPhone", "iPod", "iPod touch", "iPad":
+		return OSIOS
+	case "Macintosh":
+		return OSMacOS
+	}
+
+	name := ua.OSInfo().Name
+	switch {
+	case strings.HasPrefix(name, "Android"):
+		return OSAndroid
+	case strings.HasPrefix(name, "Windows"):
+		return OSWindows
+	case strings.HasPrefix(name, "CrOS"):
+		return OSChromeOS
+	case strings.Contains(ua.Platform(), "Linux") || ua.Platform() == "X11":
+		return OSLinux
+	}
+	return OSOther
+}
+
+// Matches reports whether every condition of the rule holds for client
+func (r Rule) Matches(client Client) bool {
+	return (r.OS == "" || r.OS == client.OS) &&
+		(r.Device == "" || r.Device == client.Device) &&
+		(r.Browser == "" || r.Browser == client.Browser)
+}
+
+// Match returns the destination of the first rule matching the visitor, false when no rule matches
+func Match(rules []Rule, userAgent string) (string, bool) {
+	if len(rules) == 0 {
+		return "", false
+	}
+	client := Classify(userAgent)
+	for _, rule := range rules {
+		if rule.Matches(client) {
+			return rule.Url, true
+		}
+	}
+	return "", false
+}
+
+// Normalize lowercases the conditions of rules and checks them, every rule needs at least one condition
+func Normalize(rules []Rule) ([]Rule, error) {
+	if len(rules) > MaxRules {
+		return nil, fmt.Errorf("a link can have at most %d targeting rules", MaxRules)
+	}
+
+	normalized := make([]Rule, 0, len(rules))
+	for i, rule := range rules {
+		rule.OS = strings.ToLower(strings.TrimSpace(rule.OS))
+		rule.Device = strings.ToLower(strings.TrimSpace(rule.Device))
+		rule.Browser = strings.ToLower(strings.TrimSpace(rule.Browser))
+		rule.Url = strings.TrimSpace(rule.Url)
+
+		if rule.OS == "" && rule.Device == "" && rule.Browser == "" {
+			return nil, fmt.Errorf("rule %d needs an os, device or browser", i+1)
+		}
+		if rule.OS != "" && !slices.Contains(validOS, rule.OS) {
+			return nil, fmt.Errorf("rule %d: os must be one of %v", i+1, strings.Join(validOS, ", "))
+		}
+		if rule.Device != "" && !slices.Contains(validDevices, rule.Device) {
+			return nil, fmt.Errorf("rule %d: device must be one of %v", i+1, strings.Join(validDevices, ", "))
+		}
+		if rule.Url == "" {
+			return nil, fmt.Errorf("rule %d needs a url", i+1)
+		}
+		normalized = append(normalized, rule)
+	}
+	return normalized, nil
+}
@@ -0,0 +1,93 @@
+package targeting
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"strings"
+)
+
+// Limits of a split test
+const (
+	MaxVariants          = 10
+	MaxVariantWeight     = 1000
+	maxVariantNameLength = 50
+)
+
+// Variant is one destination of a split test, visitors are spread over variants in proportion to their weight
+type Variant struct {
+	Name   string `json:"name"`
+	Url    string `json:"url"`
+	Weight int    `json:"weight"`
+}
+
+// Pick returns the variant named sticky when there is one, otherwise a variant chosen at random by weight
+func Pick(variants []Variant, sticky string) (Variant, bool) {
+	if len(variants) == 0 {
+		return Variant{}, false
+	}
+
+	total := 0
+	for _, variant := range variants {
+		if sticky != "" && variant.Name == sticky {
+			return variant, true
+		}
+		total += variant.Weight
+	}
+	if total <= 0 {
+		return variants[0], true
+	}
+
+	n := rand.IntN(total)
+	for _, variant := range variants {
+		if n < variant.Weight {
+			return variant, true
+		}
+		n -= variant.Weight
+	}
+	return variants[len(variants)-1], true
+}
+
+// NormalizeVariants checks a split test, it needs two or more uniquely named variants with a positive weight
+func NormalizeVariants(variants []Variant) ([]Variant, error) {
+	if len(variants) == 0 {
+		return []Variant{}, nil
+	}
+	if len(variants) < 2 {
+		return nil, fmt.Errorf("a split test needs at least two variants")
+	}
+	if len(variants) > MaxVariants {
+		return nil, fmt.Errorf("a link can have at most %d variants", MaxVariants)
+	}
+
+	names := make(map[string]bool, len(variants))
+	normalized := make([]Variant, 0, len(variants))
+	for i, variant := range variants {
+		variant.Name = strings.TrimSpace(variant.Name)
+		variant.Url = strings.TrimSpace(variant.Url)
+
+		if variant.Name == "" || len(variant.Name) > maxVariantNameLength {
+			return nil, fmt.Errorf("variant %d needs a name of at most %d characters", i+1, maxVariantNameLength)
+		}
+		// names are stored in the sticky cookie as they are
+		if strings.IndexFunc(variant.Name, invalidNameRune) >= 0 {
+			return nil, fmt.Errorf("variant name %q may only contain letters, digits, - and _", variant.Name)
+		}
+		if names[variant.Name] {
+			return nil, fmt.Errorf("variant name %q is used twice", variant.Name)
+		}
+		names[variant.Name] = true
+
+		if variant.Weight < 1 || variant.Weight > MaxVariantWeight {
+			return nil, fmt.Errorf("variant %q: weight must be between 1 and %d", variant.Name, MaxVariantWeight)
+		}
+		if variant.Url == "" {
+			return nil, fmt.Errorf("variant %q needs a url", variant.Name)
+		}
+		normalized = append(normalized, variant)
+	}
+	return normalized, nil
+}
+
+func invalidNameRune(r rune) bool {
+	return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_')
+}
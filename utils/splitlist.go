@@ -0,0 +1,14 @@
+package utils
+
+import "strings"
+
+// SplitList splits a comma separated value, empty items are dropped
+func SplitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,31 @@
+package worker
+
+import (
+	"context"
+
+	"github.com/tin3ga/shortly/alias"
+	"github.com/tin3ga/shortly/internal/database"
+)
+
+// BackfillAliasKeys rewrites the alias keys of links with non ASCII aliases that do not match alias.Key.
+// The migration adding alias_key lowercased every alias, which is alias.Key for ASCII aliases only.
+// It returns the number of links updated.
+func BackfillAliasKeys(ctx context.Context, queries *database.Queries) (int, error) {
+	links, err := queries.GetNonASCIIAliases(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, link := range links {
+		key := alias.Key(link.ShortLink)
+		if key == link.AliasKey {
+			continue
+		}
+		if err := queries.SetAliasKey(ctx, database.SetAliasKeyParams{ID: link.ID, AliasKey: key}); err != nil {
+			return count, err
+		}
+		count++
+	}
+	return count, nil
+}
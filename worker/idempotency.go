@@ -0,0 +1,35 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/tin3ga/shortly/internal/database"
+)
+
+const idempotencyPurgeInterval = time.Hour
+
+// StartIdempotencyKeyPurger periodically deletes idempotency keys older than ttl until ctx is cancelled
+func StartIdempotencyKeyPurger(ctx context.Context, queries *database.Queries, ttl time.Duration) {
+	go func() {
+		ticker := time.NewTicker(idempotencyPurgeInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				count, err := queries.PurgeIdempotencyKeys(ctx, int64(ttl.Seconds()))
+				if err != nil {
+					log.Printf("Idempotency key purge failed: %v", err)
+					continue
+				}
+				if count > 0 {
+					log.Printf("Idempotency key purge: %v keys deleted", count)
+				}
+			}
+		}
+	}()
+}
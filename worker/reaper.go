@@ -0,0 +1,54 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/tin3ga/shortly/internal/database"
+)
+
+const (
+	ReaperModeArchive = "archive"
+	ReaperModePurge   = "purge"
+)
+
+// StartLinkReaper periodically archives or purges expired links until ctx is cancelled
+func StartLinkReaper(ctx context.Context, queries *database.Queries, interval time.Duration, mode string) {
+	if mode != ReaperModePurge {
+		mode = ReaperModeArchive
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				reapExpiredLinks(ctx, queries, mode)
+			}
+		}
+	}()
+}
+
+func reapExpiredLinks(ctx context.Context, queries *database.Queries, mode string) {
+	var count int64
+	var err error
+
+	if mode == ReaperModePurge {
+		count, err = queries.PurgeExpiredLinks(ctx)
+	} else {
+		count, err = queries.ArchiveExpiredLinks(ctx)
+	}
+	if err != nil {
+		log.Printf("Link reaper failed: %v", err)
+		return
+	}
+
+	if count > 0 {
+		log.Printf("Link reaper: %v expired links (%v)", count, mode)
+	}
+}
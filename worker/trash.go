@@ -0,0 +1,49 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/tin3ga/shortly/internal/database"
+)
+
+const (
+	// DefaultTrashRetention is how long deleted links stay restorable when no retention is configured
+	DefaultTrashRetention = 30 * 24 * time.Hour
+
+	trashPurgeInterval = time.Hour
+)
+
+// StartTrashPurger periodically deletes links that have been in the trash for longer than retention until ctx is cancelled
+func StartTrashPurger(ctx context.Context, queries *database.Queries, retention time.Duration) {
+	if retention <= 0 {
+		retention = DefaultTrashRetention
+	}
+
+	go func() {
+		ticker := time.NewTicker(trashPurgeInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				purgeTrashedLinks(ctx, queries, retention)
+			}
+		}
+	}()
+}
+
+func purgeTrashedLinks(ctx context.Context, queries *database.Queries, retention time.Duration) {
+	count, err := queries.PurgeTrashedLinks(ctx, int64(retention.Seconds()))
+	if err != nil {
+		log.Printf("Trash purge failed: %v", err)
+		return
+	}
+
+	if count > 0 {
+		log.Printf("Trash purge: %v links deleted for good", count)
+	}
+}